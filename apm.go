@@ -24,12 +24,14 @@ type Apm struct {
 }
 
 func (f *FluxGo) AddApm() *FluxGo {
+	f.markProvided(DependencyApm)
+
 	f.AddDependency(func() *Apm {
 		apm := Apm{}
 
 		return &apm
 	})
-	f.AddInvoke(func(lc fx.Lifecycle, apm *Apm, o *Otel) error {
+	f.addResource(func(lc fx.Lifecycle, apm *Apm, o *Otel) error {
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				var traceExporter sdktrace.SpanExporter
@@ -0,0 +1,364 @@
+package fluxgo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SubjectContextKey contextKey = "subject"
+	TenantContextKey  contextKey = "tenant"
+	ClaimsContextKey  contextKey = "claims"
+
+	defaultJWKSRefreshInterval = time.Hour
+	// jwksMissRefreshInterval limits the refreshes triggered by tokens with an unknown kid.
+	jwksMissRefreshInterval = 10 * time.Second
+)
+
+var defaultJWTAlgorithms = []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// JWTAuthOptions configures WithJWTAuth. Tokens are verified with Keys, then JWKS.
+type JWTAuthOptions struct {
+	// Keys maps a kid to its key: []byte for HS*, *rsa.PublicKey for RS*/PS*, *ecdsa.PublicKey for ES*.
+	// The "" key verifies tokens without a kid.
+	Keys map[string]any
+	JWKS *JWKS
+	// Algorithms accepted in the token header. Default: every HS, RS, PS and ES algorithm
+	Algorithms []string
+	Issuer     string
+	Audience   string
+
+	// RoleClaim, SubjectClaim and TenantClaim name the claims put in the context under
+	// RoleContextKey, SubjectContextKey and TenantContextKey. Nested claims use dots, e.g.
+	// "realm_access.roles"; for arrays the first element is used.
+	// Default: "role", "sub" and "tenant_id"
+	RoleClaim    string
+	SubjectClaim string
+	TenantClaim  string
+
+	// Optional lets requests without a token through unauthenticated; invalid tokens are still rejected.
+	Optional bool
+}
+
+// JWKS loads a JSON Web Key Set from a URL or a file.
+type JWKS struct {
+	URL  string
+	File string
+	// RefreshInterval reloads the key set; tokens with an unknown kid also reload it. Default: 1h
+	RefreshInterval time.Duration
+	// Client defaults to an http.Client with a 10 second timeout.
+	Client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// WithJWTAuth authenticates every request of the router group with a bearer JWT,
+// replying the standard 401 GlobalError when it is missing or invalid.
+func WithJWTAuth(opt JWTAuthOptions) RouterOption {
+	return jwtAuthOpt{opt: opt}
+}
+
+type jwtAuthOpt struct{ opt JWTAuthOptions }
+
+func (o jwtAuthOpt) applyRouterOption(h *Http, _ string, ctx *routerBuildCtx) {
+	ctx.handlers = append(ctx.handlers, o.opt.handler(h))
+}
+
+func (opt JWTAuthOptions) handler(h *Http) fiber.Handler {
+	algorithms := opt.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultJWTAlgorithms
+	}
+
+	parserOptions := []jwt.ParserOption{jwt.WithValidMethods(algorithms), jwt.WithExpirationRequired()}
+	if opt.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(opt.Issuer))
+	}
+	if opt.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(opt.Audience))
+	}
+	parser := jwt.NewParser(parserOptions...)
+
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" && opt.Optional {
+			return c.Next()
+		}
+
+		raw, found := strings.CutPrefix(header, "Bearer ")
+		if !found || raw == "" {
+			return h.SendError(c, errorUnauthorized())
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+			return opt.key(c.UserContext(), token)
+		}); err != nil {
+			return h.SendError(c, errorUnauthorized())
+		}
+
+		ctx := context.WithValue(c.UserContext(), ClaimsContextKey, claims)
+		for key, claim := range map[contextKey]string{
+			RoleContextKey:    defaultString(opt.RoleClaim, "role"),
+			SubjectContextKey: defaultString(opt.SubjectClaim, "sub"),
+			TenantContextKey:  defaultString(opt.TenantClaim, "tenant_id"),
+		} {
+			if value := claimString(claims, claim); value != "" {
+				ctx = context.WithValue(ctx, key, value)
+			}
+		}
+		c.SetUserContext(ctx)
+
+		return c.Next()
+	}
+}
+
+// key resolves the verification key of token by its kid.
+func (opt JWTAuthOptions) key(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if key, exists := opt.Keys[kid]; exists {
+		return key, nil
+	}
+	if opt.JWKS != nil {
+		return opt.JWKS.key(ctx, kid)
+	}
+
+	return nil, fmt.Errorf("unknown key: %q", kid)
+}
+
+func (j *JWKS) key(ctx context.Context, kid string) (any, error) {
+	j.mu.RLock()
+	key, exists := j.keys[kid]
+	stale := time.Since(j.fetchedAt) > defaultDuration(j.RefreshInterval, defaultJWKSRefreshInterval)
+	refresh := (stale || !exists) && time.Since(j.attemptedAt) > jwksMissRefreshInterval
+	j.mu.RUnlock()
+
+	if !refresh {
+		if exists {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key: %q", kid)
+	}
+
+	if err := j.Refresh(ctx); err != nil && !exists {
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if key, exists := j.keys[kid]; exists {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown key: %q", kid)
+}
+
+// Refresh reloads the key set. On failure the previous keys are kept.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = time.Now()
+	j.mu.Unlock()
+
+	content, err := j.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseJWKS(content)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if j.File != "" {
+		content, err := os.ReadFile(j.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS: %w", err)
+		}
+		return content, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := j.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", res.StatusCode)
+	}
+
+	var content json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&content); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	return content, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS decodes the RSA, EC and symmetric keys of a JSON Web Key Set by kid.
+// Keys of other types, or not used for signatures, are skipped.
+func ParseJWKS(content []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := map[string]any{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, exists := curves[k.Crv]
+		if !exists {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "oct":
+		return decode(k.K)
+	}
+
+	return nil, nil
+}
+
+// claimString reads a dotted claim path as a string, taking the first element of arrays.
+func claimString(claims jwt.MapClaims, path string) string {
+	var value any = map[string]any(claims)
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = object[part]
+	}
+
+	if values, ok := value.([]any); ok {
+		if len(values) == 0 {
+			return ""
+		}
+		value = values[0]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return ""
+}
+
+func errorUnauthorized() *GlobalError {
+	return &GlobalError{
+		Message: "Unauthorized",
+		Code:    "error.unauthorized",
+		Status:  fiber.StatusUnauthorized,
+		Success: false,
+	}
+}
+
+func defaultString(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func defaultDuration(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+
+	return fallback
+}
@@ -0,0 +1,130 @@
+package fluxgo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type authTestHandler struct{}
+
+func (h *authTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	ctx := c.UserContext()
+	return &GlobalResponse{Status: 200, Content: fiber.Map{
+		"role":    ctx.Value(RoleContextKey),
+		"subject": ctx.Value(SubjectContextKey),
+		"tenant":  ctx.Value(TenantContextKey),
+	}}, nil
+}
+
+func TestJWTAuth(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+	}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Permissions: &Permissions{"admin": {{Action: "read", Subject: "user"}}}}, func(h HttpConfigData) {
+		h.CreateRouter("/api", WithJWTAuth(JWTAuthOptions{
+			Keys:        map[string]any{"": secret},
+			JWKS:        &JWKS{File: jwksFile},
+			Issuer:      "auth",
+			RoleClaim:   "realm_access.roles",
+			TenantClaim: "org",
+		}))
+		h.CreateRouter("/open", WithJWTAuth(JWTAuthOptions{Keys: map[string]any{"": secret}, Optional: true}))
+	})
+	flux.AddModule(Module("test").
+		AddHandler(func() *authTestHandler { return &authTestHandler{} }).
+		Route(
+			GET[authTestHandler]("/api", "/me", RouteIncome{Permission: &RoutePermission{Action: "read", Subject: "user"}}),
+			GET[authTestHandler]("/open", "/me", RouteIncome{}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss":          "auth",
+			"sub":          "user-1",
+			"org":          "acme",
+			"exp":          time.Now().Add(time.Minute).Unix(),
+			"realm_access": map[string]any{"roles": []string{"admin", "view"}},
+		}
+		for key, value := range overrides {
+			claims[key] = value
+		}
+		return claims
+	}
+	sign := func(method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) *Headers {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return &Headers{"Authorization": "Bearer " + signed}
+	}
+
+	t.Run("Should map the claims of an HS256 token to the context", func(t *testing.T) {
+		status, body := RunTestRequest(http, "GET", "/api/me", nil, sign(jwt.SigningMethodHS256, "", secret, claims(nil)))
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "admin", body["role"])
+		assert.Equal(t, "user-1", body["subject"])
+		assert.Equal(t, "acme", body["tenant"])
+	})
+
+	t.Run("Should verify RS256 and ES256 tokens with the JWKS", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "GET", "/api/me", nil, sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)))
+		assert.Equal(t, 200, status)
+
+		status, _ = RunTestRequestRaw(http, "GET", "/api/me", nil, sign(jwt.SigningMethodES256, "ec-1", ecKey, claims(nil)))
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should reply 401 for missing, expired, foreign or misissued tokens", func(t *testing.T) {
+		otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+		for _, headers := range []*Headers{
+			nil,
+			{"Authorization": "Basic dXNlcjpwYXNz"},
+			sign(jwt.SigningMethodHS256, "", secret, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})),
+			sign(jwt.SigningMethodHS256, "", secret, claims(jwt.MapClaims{"iss": "other"})),
+			sign(jwt.SigningMethodRS256, "rsa-1", otherKey, claims(nil)),
+			sign(jwt.SigningMethodRS256, "unknown", rsaKey, claims(nil)),
+		} {
+			status, body := RunTestRequest(http, "GET", "/api/me", nil, headers)
+			assert.Equal(t, 401, status)
+			assert.Equal(t, "error.unauthorized", body["code"])
+		}
+	})
+
+	t.Run("Should let requests without a token through when optional", func(t *testing.T) {
+		status, body := RunTestRequest(http, "GET", "/open/me", nil, nil)
+		assert.Equal(t, 200, status)
+		assert.Nil(t, body["role"])
+
+		status, _ = RunTestRequestRaw(http, "GET", "/open/me", nil, &Headers{"Authorization": "Bearer invalid"})
+		assert.Equal(t, 401, status)
+	})
+}
@@ -10,3 +10,11 @@ type ICache interface {
 	Store(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Invalidate(ctx context.Context, keys []string) error
 }
+
+// ICacheReserver is implemented by caches that can store a key only when it is absent,
+// which makes idempotent routes safe against concurrent duplicates. Delete removes the
+// exact keys, unlike Invalidate, which may treat them as patterns.
+type ICacheReserver interface {
+	StoreIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
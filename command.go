@@ -0,0 +1,301 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/golang-migrate/migrate/v4"
+	"go.uber.org/fx"
+)
+
+// CommandFunc runs a CLI command with the arguments that follow its name.
+type CommandFunc func(ctx context.Context, args []string) error
+
+// Command is a CLI subcommand dispatched by FluxGo.Execute.
+type Command struct {
+	// Name may contain spaces for nested commands, e.g. "cron run".
+	Name        string
+	Usage       string
+	Description string
+	// Run is either a CommandFunc or a function resolved by fx that returns a CommandFunc,
+	// e.g. func(db *fluxgo.Database) fluxgo.CommandFunc.
+	Run interface{}
+	// serve marks the command that runs the long-lived application instead of a one-shot task.
+	serve bool
+}
+
+const commandResultName = `name:"fluxgo_command"`
+
+// AddCommand registers a CLI command available through Execute.
+func (f *FluxGo) AddCommand(cmd Command) *FluxGo {
+	f.commands = append(f.commands, cmd)
+
+	return f
+}
+
+// AddCommand registers a CLI command owned by the module.
+func (m *FluxModule) AddCommand(cmd Command) *FluxModule {
+	m.commands = append(m.commands, cmd)
+
+	return m
+}
+
+// AddMigrations configures the migrations used by the "migrate" and "seed" commands.
+func (f *FluxGo) AddMigrations(opt DatabaseMigrationsOptions) *FluxGo {
+	f.migrations = &opt
+
+	return f
+}
+
+// Execute dispatches os.Args to a registered command, defaulting to "serve".
+// It exits the process with status 1 when the command fails.
+func (f *FluxGo) Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := f.ExecuteArgs(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// ExecuteArgs dispatches args to the matching command.
+func (f *FluxGo) ExecuteArgs(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	commands := f.allCommands()
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		f.printUsage(commands)
+		return nil
+	}
+
+	cmd, rest := matchCommand(commands, args)
+	if cmd == nil {
+		f.printUsage(commands)
+		return fmt.Errorf("unknown command: %s", strings.Join(args, " "))
+	}
+
+	if cmd.serve {
+		f.Run()
+		return nil
+	}
+
+	return f.runCommand(ctx, *cmd, rest)
+}
+
+// matchCommand picks the command whose name matches the longest prefix of args.
+func matchCommand(commands []Command, args []string) (*Command, []string) {
+	var found *Command
+	size := 0
+
+	for i := range commands {
+		parts := strings.Fields(commands[i].Name)
+		if len(parts) <= size || len(parts) > len(args) {
+			continue
+		}
+		if strings.Join(parts, " ") == strings.Join(args[:len(parts)], " ") {
+			found = &commands[i]
+			size = len(parts)
+		}
+	}
+
+	if found == nil {
+		return nil, nil
+	}
+
+	return found, args[size:]
+}
+
+// runCommand builds the application without starting any ingress subsystem,
+// starts its resources, runs the command and stops the application.
+func (f *FluxGo) runCommand(ctx context.Context, cmd Command, args []string) error {
+	f.commandMode = true
+
+	constructor := cmd.Run
+	switch fn := cmd.Run.(type) {
+	case CommandFunc:
+		constructor = func() CommandFunc { return fn }
+	case func(ctx context.Context, args []string) error:
+		constructor = func() CommandFunc { return fn }
+	}
+
+	var run CommandFunc
+
+	opts := append(f.GetFxConfig(),
+		resolveResult(commandResultName, constructor, func(fn CommandFunc) { run = fn }, false),
+		fx.NopLogger,
+	)
+
+	app := fx.New(opts...)
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+
+	err := run(ctx, args)
+
+	if stopErr := app.Stop(context.Background()); stopErr != nil {
+		err = errors.Join(err, stopErr)
+	}
+
+	return err
+}
+
+func (f *FluxGo) allCommands() []Command {
+	commands := append([]Command{}, f.builtinCommands()...)
+	commands = append(commands, f.commands...)
+	for _, module := range f.modules {
+		commands = append(commands, module.commands...)
+	}
+
+	return commands
+}
+
+func (f *FluxGo) printUsage(commands []Command) {
+	w := tabwriter.NewWriter(f.output(), 0, 2, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Usage: %s <command> [args]\n\nCommands:\n", f.GetCleanName())
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", strings.TrimSpace(cmd.Name+" "+cmd.Usage), cmd.Description)
+	}
+
+	_ = w.Flush()
+}
+
+func (f *FluxGo) output() io.Writer {
+	if f.stdout != nil {
+		return f.stdout
+	}
+
+	return os.Stdout
+}
+
+func (f *FluxGo) builtinCommands() []Command {
+	return []Command{
+		{Name: "serve", Description: "Start the application (default)", serve: true},
+		{Name: "migrate up", Description: "Apply all pending migrations", Run: f.migrateCommand(func(m *migrate.Migrate, _ []string) error {
+			return m.Up()
+		})},
+		{Name: "migrate down", Usage: "[steps]", Description: "Roll back migrations (default 1 step)", Run: f.migrateCommand(func(m *migrate.Migrate, args []string) error {
+			steps := 1
+			if len(args) > 0 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid steps: %s", args[0])
+				}
+				steps = n
+			}
+			return m.Steps(-steps)
+		})},
+		{Name: "migrate status", Description: "Print the current migration version", Run: f.migrateCommand(func(m *migrate.Migrate, _ []string) error {
+			version, dirty, err := m.Version()
+			if errors.Is(err, migrate.ErrNilVersion) {
+				fmt.Fprintln(f.output(), "No migrations applied")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(f.output(), "Version: %d\nDirty: %t\n", version, dirty)
+			return nil
+		})},
+		{Name: "seed", Description: "Run the configured seeds", Run: func(db *Database) CommandFunc {
+			return func(ctx context.Context, _ []string) error {
+				if f.migrations == nil || f.migrations.Seeds == nil {
+					return fmt.Errorf("no seeds configured: call FluxGo.AddMigrations with Seeds")
+				}
+				return db.RunSeeds(ctx, *f.migrations)
+			}
+		}},
+		{Name: "routes", Description: "Print all registered routes, topics, cron jobs, gRPC services and tools", Run: func() CommandFunc {
+			return func(ctx context.Context, _ []string) error {
+				inv := f.Inventory()
+
+				w := tabwriter.NewWriter(f.output(), 0, 2, 2, ' ', 0)
+				fmt.Fprintln(w, "KIND\tNAME\tMODULE\tDETAILS")
+				for _, route := range inv.Routes {
+					details := ""
+					if route.Permission != nil {
+						details = "permission=" + route.Permission.String()
+					}
+					if route.CacheTTL != "" {
+						details = strings.TrimSpace(details + " cache=" + route.CacheTTL)
+					}
+					fmt.Fprintf(w, "http\t%s %s\t%s\t%s\n", route.Method, route.Path, route.Module, details)
+				}
+				for _, topic := range inv.Topics {
+					fmt.Fprintf(w, "topic\t%s\t%s\t%s\n", topic.Topic, topic.Module, topic.Handler)
+				}
+				for _, cron := range inv.Crons {
+					fmt.Fprintf(w, "cron\t%s\t\t%s\n", cron.Name, cron.Spec)
+				}
+				for _, service := range inv.Grpc {
+					fmt.Fprintf(w, "grpc\t%s\t\t%s\n", service.Service, strings.Join(service.Methods, ","))
+				}
+				for _, tool := range inv.Tools {
+					fmt.Fprintf(w, "tool\t%s\t\t%s\n", tool.Name, tool.Description)
+				}
+				return w.Flush()
+			}
+		}},
+		{Name: "cron run", Usage: "<job>", Description: "Run a cron job once", Run: func(cron *Cron) CommandFunc {
+			return func(ctx context.Context, args []string) error {
+				if len(args) == 0 {
+					return fmt.Errorf("missing job name, available: %s", strings.Join(cron.Names(), ", "))
+				}
+				return cron.Run(ctx, args[0])
+			}
+		}},
+		{Name: "openapi export", Usage: "[prefix] [version]", Description: "Print the OpenAPI document as JSON, optionally of one API version", Run: func(http *Http) CommandFunc {
+			return func(ctx context.Context, args []string) error {
+				prefix := ""
+				if len(args) > 0 {
+					prefix = args[0]
+				}
+
+				encoder := json.NewEncoder(f.output())
+				encoder.SetIndent("", "  ")
+				if len(args) > 1 {
+					return encoder.Encode(http.OpenAPIVersionSpec(prefix, args[1]))
+				}
+				return encoder.Encode(http.OpenAPISpec(prefix))
+			}
+		}},
+	}
+}
+
+func (f *FluxGo) migrateCommand(fn func(m *migrate.Migrate, args []string) error) func(db *Database) CommandFunc {
+	return func(db *Database) CommandFunc {
+		return func(ctx context.Context, args []string) error {
+			if f.migrations == nil {
+				return fmt.Errorf("no migrations configured: call FluxGo.AddMigrations")
+			}
+
+			err := db.Migrate(*f.migrations, func(m *migrate.Migrate) error {
+				return fn(m, args)
+			})
+			if errors.Is(err, migrate.ErrNoChange) {
+				fmt.Fprintln(f.output(), "No changes to be applied")
+				return nil
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package fluxgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type commandTestCron struct {
+	runs int
+}
+
+func (h *commandTestCron) HandleCron(ctx context.Context) error {
+	h.runs++
+	return nil
+}
+
+func TestCommand(t *testing.T) {
+	t.Run("Should run a custom command with dependencies", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddDependency(func() *Env { return &Env{Env: "test"} })
+
+		var received []string
+		flux.AddCommand(Command{Name: "greet", Run: func(env *Env) CommandFunc {
+			return func(ctx context.Context, args []string) error {
+				received = append(args, env.Env)
+				return nil
+			}
+		}})
+
+		err := flux.ExecuteArgs(context.Background(), []string{"greet", "world"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"world", "test"}, received)
+	})
+
+	t.Run("Should return the command error", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddCommand(Command{Name: "fail", Run: func(ctx context.Context, args []string) error {
+			return errors.New("failed")
+		}})
+
+		assert.EqualError(t, flux.ExecuteArgs(context.Background(), []string{"fail"}), "failed")
+	})
+
+	t.Run("Should reject unknown commands", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.stdout = &bytes.Buffer{}
+
+		assert.Error(t, flux.ExecuteArgs(context.Background(), []string{"unknown"}))
+	})
+
+	t.Run("Should run a cron job by name without starting the scheduler", func(t *testing.T) {
+		handler := &commandTestCron{}
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddCron()
+		flux.AddModule(Module("jobs").
+			AddHandler(func() *commandTestCron { return handler }).
+			Route(CronDef[commandTestCron]("0 0 1 1 *")))
+
+		err := flux.ExecuteArgs(context.Background(), []string{"cron", "run", "jobs.commandTestCron"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, handler.runs)
+	})
+
+	t.Run("Should not reuse names of named cron jobs", func(t *testing.T) {
+		handler := &commandTestCron{}
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddCron()
+		flux.AddInvoke(func(cron *Cron) error {
+			if err := cron.RegisterNamed("job_2", "0 0 1 1 *", func(ctx context.Context) error { return nil }); err != nil {
+				return err
+			}
+			return cron.Register("0 0 1 1 *", handler.HandleCron)
+		})
+
+		err := flux.ExecuteArgs(context.Background(), []string{"cron", "run", "job_3"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, handler.runs)
+	})
+
+	t.Run("Should print registered routes", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.stdout = out
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(HttpConfigData) {})
+		flux.AddModule(Module("test").
+			AddHandler(func() *rolesTestHandler { return &rolesTestHandler{} }).
+			Route(GET[rolesTestHandler]("", "/ping", RouteIncome{})))
+
+		err := flux.ExecuteArgs(context.Background(), []string{"routes"})
+
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "GET")
+		assert.Contains(t, out.String(), "/ping")
+	})
+}
@@ -0,0 +1,251 @@
+package fluxgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// ConfigOptions configures a reloadable Config.
+type ConfigOptions[T any] struct {
+	// Env describes the sources read on every load, see ParseEnvE.
+	Env EnvOptions
+	// WatchInterval polls ConfigFile, its overlay and LoadFromFile for changes. Zero disables polling.
+	WatchInterval time.Duration
+	// DisableSignal stops reloading on SIGHUP.
+	DisableSignal bool
+	// Validate runs after the struct validation; a non-nil error rejects the new value.
+	Validate func(value T) error
+}
+
+// Config holds a configuration value that can be reloaded at runtime.
+// A reload that fails to parse or validate keeps the current value.
+type Config[T any] struct {
+	opt   ConfigOptions[T]
+	value atomic.Pointer[T]
+
+	reloading   sync.Mutex
+	mu          sync.Mutex
+	subscribers map[int]func(old, new T)
+	nextID      int
+	modTimes    map[string]time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewConfig loads the first value of the configuration.
+func NewConfig[T any](opt ConfigOptions[T]) (*Config[T], error) {
+	c := &Config[T]{opt: opt, subscribers: map[int]func(old, new T){}}
+
+	value, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.value.Store(&value)
+	c.modTimes = c.readModTimes()
+
+	return c, nil
+}
+
+// AddConfig provides *Config[T] and reloads it on SIGHUP or when its files change.
+//
+// Usage: fluxgo.AddConfig(flux, fluxgo.ConfigOptions[AppConfig]{Env: fluxgo.EnvOptions{ConfigFile: "config.yaml"}})
+func AddConfig[T any](f *FluxGo, opt ConfigOptions[T]) *FluxGo {
+	f.AddDependency(func() (*Config[T], error) {
+		return NewConfig[T](opt)
+	})
+	f.addResource(func(lc fx.Lifecycle, config *Config[T]) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				config.watch(func(err error) {
+					f.LogError("CONFIG", fmt.Sprintf("Reload failed: %v", err))
+				})
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				config.unwatch()
+				return nil
+			},
+		})
+	})
+
+	return f
+}
+
+// Get returns the current value.
+func (c *Config[T]) Get() T {
+	return *c.value.Load()
+}
+
+// Subscribe calls fn with the previous and the new value after every successful reload.
+// The returned function removes the subscription.
+func (c *Config[T]) Subscribe(fn func(old, new T)) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID
+	c.nextID++
+	c.subscribers[id] = fn
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// Reload re-reads every source, validates the result and swaps it in.
+func (c *Config[T]) Reload() error {
+	c.reloading.Lock()
+	defer c.reloading.Unlock()
+
+	value, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	old := c.value.Swap(&value)
+
+	c.mu.Lock()
+	c.modTimes = c.readModTimes()
+	subscribers := make([]func(old, new T), 0, len(c.subscribers))
+	for _, fn := range c.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	c.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(*old, value)
+	}
+
+	return nil
+}
+
+func (c *Config[T]) load() (T, error) {
+	value, err := ParseEnvE[T](c.opt.Env)
+	if err != nil {
+		return value, err
+	}
+
+	if c.opt.Validate != nil {
+		if err := c.opt.Validate(value); err != nil {
+			return value, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	return value, nil
+}
+
+// files lists the files read by load, including overlays of any environment.
+func (c *Config[T]) files() []string {
+	files := []string{}
+
+	if c.opt.Env.ConfigFile != "" {
+		if path, found := findFile(c.opt.Env.ConfigFile); found {
+			ext := filepath.Ext(path)
+			overlays, _ := filepath.Glob(strings.TrimSuffix(path, ext) + ".*" + ext)
+			files = append(files, path)
+			files = append(files, overlays...)
+		}
+	}
+	if c.opt.Env.LoadFromFile != nil {
+		if path, found := findFile(*c.opt.Env.LoadFromFile); found {
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
+
+func (c *Config[T]) readModTimes() map[string]time.Time {
+	times := map[string]time.Time{}
+	for _, file := range c.files() {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		}
+	}
+
+	return times
+}
+
+// changed reports whether a watched file changed since the last check.
+func (c *Config[T]) changed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.readModTimes()
+	previous := c.modTimes
+	c.modTimes = current
+
+	if len(current) != len(previous) {
+		return true
+	}
+	for file, modTime := range current {
+		if !previous[file].Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Config[T]) watch(onError func(err error)) {
+	if c.opt.DisableSignal && c.opt.WatchInterval <= 0 {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	signals := make(chan os.Signal, 1)
+	if !c.opt.DisableSignal {
+		signal.Notify(signals, syscall.SIGHUP)
+	}
+
+	go func() {
+		defer close(c.done)
+		defer signal.Stop(signals)
+
+		var tick <-chan time.Time
+		if c.opt.WatchInterval > 0 {
+			ticker := time.NewTicker(c.opt.WatchInterval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-signals:
+			case <-tick:
+				if !c.changed() {
+					continue
+				}
+			}
+
+			if err := c.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}()
+}
+
+func (c *Config[T]) unwatch() {
+	if c.stop == nil {
+		return
+	}
+
+	close(c.stop)
+	<-c.done
+}
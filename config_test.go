@@ -0,0 +1,142 @@
+package fluxgo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type configTestValues struct {
+	Threshold int    `env:"THRESHOLD" validate:"required"`
+	LogLevel  string `env:"LOG_LEVEL"`
+}
+
+func writeConfigTestFile(t *testing.T, path, content string, modTime time.Time) {
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("Should reload, swap and notify subscribers", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfigTestFile(t, path, "threshold: 1\n", time.Now().Add(-time.Hour))
+
+		config, err := NewConfig(ConfigOptions[configTestValues]{Env: EnvOptions{ConfigFile: path, Validate: true}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, config.Get().Threshold)
+
+		changes := [][2]int{}
+		config.Subscribe(func(old, new configTestValues) {
+			changes = append(changes, [2]int{old.Threshold, new.Threshold})
+		})
+
+		writeConfigTestFile(t, path, "threshold: 2\n", time.Now())
+		assert.NoError(t, config.Reload())
+
+		assert.Equal(t, 2, config.Get().Threshold)
+		assert.Equal(t, [][2]int{{1, 2}}, changes)
+	})
+
+	t.Run("Should keep the current value when the new one is invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfigTestFile(t, path, "threshold: 5\n", time.Now())
+
+		config, err := NewConfig(ConfigOptions[configTestValues]{
+			Env: EnvOptions{ConfigFile: path, Validate: true},
+			Validate: func(value configTestValues) error {
+				if value.Threshold > 10 {
+					return errors.New("threshold too high")
+				}
+				return nil
+			},
+		})
+		assert.NoError(t, err)
+
+		writeConfigTestFile(t, path, "threshold: 0\n", time.Now())
+		assert.ErrorContains(t, config.Reload(), "failed on 'required' validation")
+
+		writeConfigTestFile(t, path, "threshold: 50\n", time.Now())
+		assert.ErrorContains(t, config.Reload(), "threshold too high")
+
+		assert.Equal(t, 5, config.Get().Threshold)
+	})
+
+	t.Run("Should stop notifying after unsubscribe", func(t *testing.T) {
+		t.Setenv("THRESHOLD", "1")
+
+		config, err := NewConfig(ConfigOptions[configTestValues]{})
+		assert.NoError(t, err)
+
+		calls := 0
+		unsubscribe := config.Subscribe(func(old, new configTestValues) { calls++ })
+		assert.NoError(t, config.Reload())
+		unsubscribe()
+		assert.NoError(t, config.Reload())
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Should reload when a watched file changes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfigTestFile(t, path, "threshold: 1\n", time.Now().Add(-time.Hour))
+
+		config, err := NewConfig(ConfigOptions[configTestValues]{
+			Env:           EnvOptions{ConfigFile: path},
+			WatchInterval: 10 * time.Millisecond,
+			DisableSignal: true,
+		})
+		assert.NoError(t, err)
+
+		config.watch(func(err error) { t.Error(err) })
+		defer config.unwatch()
+
+		writeConfigTestFile(t, path, "threshold: 3\n", time.Now())
+
+		assert.Eventually(t, func() bool { return config.Get().Threshold == 3 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Should change the logger level at runtime", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.ConfigLogger(LoggerOptions{Level: "warn"})
+
+		var logger *Logger
+		flux.AddInvoke(func(l *Logger) { logger = l })
+		assert.NoError(t, fx.New(flux.GetFxConfig()...).Err())
+
+		assert.Equal(t, slog.LevelWarn, logger.GetLevel())
+		assert.False(t, logger.Enabled(context.Background(), slog.LevelInfo))
+
+		assert.NoError(t, logger.SetLevel("info"))
+		assert.Equal(t, slog.LevelInfo, logger.GetLevel())
+		assert.Error(t, logger.SetLevel("verbose"))
+	})
+
+	t.Run("Should fall back to info on an unknown logger level", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.ConfigLogger(LoggerOptions{Level: "verbose"})
+
+		var logger *Logger
+		flux.AddInvoke(func(l *Logger) { logger = l })
+		assert.NoError(t, fx.New(flux.GetFxConfig()...).Err())
+
+		assert.Equal(t, slog.LevelInfo, logger.GetLevel())
+	})
+
+	t.Run("Should replace HTTP permissions at runtime", func(t *testing.T) {
+		http := &Http{}
+		ctx := context.WithValue(context.Background(), RoleContextKey, "user")
+		perm := &RoutePermission{Action: "read", Subject: "report"}
+
+		assert.Equal(t, 403, http.checkPermission(ctx, perm, nil).Status)
+
+		http.SetPermissions(Permissions{"user": {{Action: "read", Subject: "report"}}})
+		assert.Nil(t, http.checkPermission(ctx, perm, nil))
+	})
+}
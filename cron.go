@@ -2,6 +2,7 @@ package fluxgo
 
 import (
 	"context"
+	"fmt"
 	"log"
 
 	"github.com/go-co-op/gocron/v2"
@@ -11,12 +12,16 @@ import (
 type Cron struct {
 	scheduler gocron.Scheduler
 	jobs      []gocron.Job
+	tasks     map[string]CronHandler
+	specs     map[string]string
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
 func (f *FluxGo) AddCron() *FluxGo {
+	f.markProvided(DependencyCron)
+
 	f.AddDependency(func() *Cron {
 		s, err := gocron.NewScheduler()
 		if err != nil {
@@ -27,13 +32,21 @@ func (f *FluxGo) AddCron() *FluxGo {
 		cron := Cron{
 			scheduler: s,
 			jobs:      make([]gocron.Job, 0),
+			tasks:     make(map[string]CronHandler),
+			specs:     make(map[string]string),
 			ctx:       ctx,
 			cancel:    cancel,
 		}
 
+		f.sources.cron = &cron
+
 		return &cron
 	})
 	f.AddInvoke(func(lc fx.Lifecycle, cron *Cron) error {
+		if !f.isServing(RoleCron) {
+			return nil
+		}
+
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				cron.scheduler.Start()
@@ -55,16 +68,57 @@ func (f *FluxGo) AddCron() *FluxGo {
 	return f
 }
 
+// Register schedules fun under a generated job_N name, skipping names already taken.
 func (c *Cron) Register(crontab string, fun CronHandler) error {
+	n := len(c.jobs) + 1
+	for {
+		if _, exists := c.tasks[fmt.Sprintf("job_%d", n)]; !exists {
+			break
+		}
+		n++
+	}
+
+	return c.RegisterNamed(fmt.Sprintf("job_%d", n), crontab, fun)
+}
+
+// RegisterNamed schedules fun under a unique name so it can also be triggered manually with Run.
+func (c *Cron) RegisterNamed(name, crontab string, fun CronHandler) error {
+	if _, exists := c.tasks[name]; exists {
+		return fmt.Errorf("cron job %s already registered", name)
+	}
+
 	j, err := c.scheduler.NewJob(
 		gocron.CronJob(crontab, false),
 		gocron.NewTask(fun),
+		gocron.WithName(name),
 	)
 	if err != nil {
 		return err
 	}
 
 	c.jobs = append(c.jobs, j)
+	c.tasks[name] = fun
+	c.specs[name] = crontab
 
 	return nil
 }
+
+// Run executes the named job immediately, outside of its schedule.
+func (c *Cron) Run(ctx context.Context, name string) error {
+	fun, exists := c.tasks[name]
+	if !exists {
+		return fmt.Errorf("cron job %s not found", name)
+	}
+
+	return fun(ctx)
+}
+
+// Names returns the names of all registered jobs.
+func (c *Cron) Names() []string {
+	names := make([]string, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		names = append(names, job.Name())
+	}
+
+	return names
+}
@@ -2,18 +2,20 @@ package fluxgo
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path"
 	"sync"
+	"sync/atomic"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/uptrace/opentelemetry-go-extra/otelsql"
 	"github.com/uptrace/opentelemetry-go-extra/otelsqlx"
 	"go.opentelemetry.io/otel/attribute"
@@ -38,8 +40,13 @@ type Database struct {
 	mainReplicaDB *sqlx.DB
 }
 type DatabaseConn struct {
-	Dsn  string
-	Type string
+	Dsn string
+	// DsnSecret is the key of the DSN in FluxGo.AddSecrets, used instead of Dsn.
+	// When the secret rotates new connections use the new DSN and idle ones are closed.
+	DsnSecret string
+	Type      string
+	// MaxIdleConns is the size of the idle pool, kept across secret rotations. Default: 2
+	MaxIdleConns int
 }
 type DatabaseOptions struct {
 	Name      string
@@ -50,6 +57,8 @@ func (f *FluxGo) AddDatabase(data DatabaseOptions) *FluxGo {
 	f.db.mu.Lock()
 	defer f.db.mu.Unlock()
 
+	f.markProvided(DependencyDatabase)
+
 	name := "default"
 	if data.Name != "" {
 		name = data.Name
@@ -65,10 +74,18 @@ func (f *FluxGo) AddDatabase(data DatabaseOptions) *FluxGo {
 	}
 
 	for _, item := range data.Instances {
-		db, err := otelsqlx.Open("postgres", item.Dsn, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+		var db *sqlx.DB
+		var err error
+
+		if item.DsnSecret != "" {
+			db, err = f.openWithSecret(name, item)
+		} else {
+			db, err = otelsqlx.Open("postgres", item.Dsn, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+		}
 		if err != nil {
 			log.Fatalln("Error to create database client", err)
 		}
+		db.SetMaxIdleConns(item.maxIdleConns())
 
 		switch item.Type {
 		case "replica":
@@ -80,8 +97,16 @@ func (f *FluxGo) AddDatabase(data DatabaseOptions) *FluxGo {
 
 	f.db.dbs[name] = &database
 
+	checkName := "database"
+	if name != "default" {
+		checkName = "database:" + name
+	}
+	f.AddHealthCheck(checkName, func(ctx context.Context) error {
+		return f.db.PingNamed(ctx, name)
+	})
+
 	f.db.once.Do(func() {
-		f.AddInvoke(func(lc fx.Lifecycle, db *Database, apm *Apm) error {
+		f.addResource(func(lc fx.Lifecycle, db *Database, apm *Apm) error {
 			f.db.apm = apm
 
 			lc.Append(fx.Hook{
@@ -107,19 +132,107 @@ func (f *FluxGo) AddDatabase(data DatabaseOptions) *FluxGo {
 	return f
 }
 
+// openWithSecret opens a pool whose DSN is read from secrets and follows its rotations.
+func (f *FluxGo) openWithSecret(name string, conn DatabaseConn) (*sqlx.DB, error) {
+	key := conn.DsnSecret
+	if f.secrets == nil {
+		return nil, fmt.Errorf("database %s: DsnSecret requires FluxGo.AddSecrets", name)
+	}
+
+	dsn, err := f.secrets.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := newRotatingConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlx.NewDb(otelsql.OpenDB(connector, otelsql.WithAttributes(semconv.DBSystemPostgreSQL)), "postgres")
+
+	f.secrets.OnRotate(key, func(dsn string) {
+		if err := connector.rotate(dsn); err != nil {
+			f.LogError("DATABASE", fmt.Sprintf("Failed to rotate credentials of %s: %v", name, err))
+			return
+		}
+
+		// Drop idle connections opened with the previous credentials.
+		db.SetMaxIdleConns(0)
+		db.SetMaxIdleConns(conn.maxIdleConns())
+		f.Log("DATABASE", fmt.Sprintf("Credentials of %s rotated", name))
+	})
+
+	return db, nil
+}
+
+// defaultMaxIdleConns mirrors the database/sql default.
+const defaultMaxIdleConns = 2
+
+func (c DatabaseConn) maxIdleConns() int {
+	if c.MaxIdleConns > 0 {
+		return c.MaxIdleConns
+	}
+
+	return defaultMaxIdleConns
+}
+
+// rotatingConnector opens connections with the latest DSN.
+type rotatingConnector struct {
+	current atomic.Pointer[pq.Connector]
+}
+
+func newRotatingConnector(dsn string) (*rotatingConnector, error) {
+	c := &rotatingConnector{}
+	if err := c.rotate(dsn); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+func (c *rotatingConnector) rotate(dsn string) error {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return err
+	}
+	c.current.Store(connector)
+
+	return nil
+}
+func (c *rotatingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.current.Load().Connect(ctx)
+}
+func (c *rotatingConnector) Driver() driver.Driver {
+	return c.current.Load().Driver()
+}
+
 func (d *Database) Connect(ctx context.Context) error {
 	var err error
 
-	for _, dbs := range d.dbs {
-		for _, db := range dbs.primaryDBs {
-			if e := db.PingContext(ctx); e != nil {
-				err = multierr.Append(err, e)
-			}
+	for name := range d.dbs {
+		if e := d.PingNamed(ctx, name); e != nil {
+			err = multierr.Append(err, e)
 		}
-		for _, db := range dbs.replicaDBs {
-			if e := db.PingContext(ctx); e != nil {
-				err = multierr.Append(err, e)
-			}
+	}
+
+	return err
+}
+func (d *Database) PingNamed(ctx context.Context, name string) error {
+	dbs, exists := d.dbs[name]
+	if !exists {
+		return fmt.Errorf("no database configured with name %s", name)
+	}
+
+	var err error
+
+	for _, db := range dbs.primaryDBs {
+		if e := db.PingContext(ctx); e != nil {
+			err = multierr.Append(err, e)
+		}
+	}
+	for _, db := range dbs.replicaDBs {
+		if e := db.PingContext(ctx); e != nil {
+			err = multierr.Append(err, e)
 		}
 	}
 
@@ -232,47 +345,22 @@ type DatabaseMigrationsOptions struct {
 }
 
 func (f *FluxGo) RunMigrations(ctx context.Context, opt DatabaseMigrationsOptions) error {
+	f.commandMode = true
+
 	opts := append(f.GetFxConfig(), fx.Invoke(func(lc fx.Lifecycle, db *Database) {
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				log.Println("Starting migrations...")
 
-				wd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("error to get pwd: %v", err)
-				}
-
-				joined := path.Join(wd, opt.Dir)
-				migrationsDirFile := "file://" + joined
-
-				var postgresConfig = opt.Config
-				if postgresConfig == nil {
-					postgresConfig = &postgres.Config{}
-				}
-
-				driver, err := postgres.WithInstance(db.WriteDB().DB, postgresConfig)
-				if err != nil {
-					return fmt.Errorf("error to get pg driver: %v", err)
+				err := db.Migrate(opt, func(migrator *migrate.Migrate) error {
+					return migrator.Up()
+				})
+				if errors.Is(err, migrate.ErrNoChange) {
+					log.Println("No changes to be applied")
+					return nil
 				}
-
-				migrator, err := migrate.NewWithDatabaseInstance(migrationsDirFile, "postgres", driver)
 				if err != nil {
-					return fmt.Errorf("unable to create migration: %v", err)
-				}
-				defer func() {
-					if err1, err2 := migrator.Close(); err1 != nil || err2 != nil {
-						log.Printf("error closing migrator: %v", err1)
-						log.Printf("error closing migrator: %v", err2)
-					}
-				}()
-
-				if err := migrator.Up(); err != nil {
-					if errors.Is(err, migrate.ErrNoChange) {
-						log.Println("No changes to be applied")
-						return nil
-					} else {
-						return fmt.Errorf("unable to apply migrations %v", err)
-					}
+					return fmt.Errorf("unable to apply migrations %v", err)
 				}
 
 				log.Println("Migrations done!")
@@ -280,8 +368,8 @@ func (f *FluxGo) RunMigrations(ctx context.Context, opt DatabaseMigrationsOption
 				if opt.Seeds != nil {
 					log.Println("Starting seeds...")
 
-					if _, err := db.WriteDB().ExecContext(ctx, *opt.Seeds); err != nil {
-						return fmt.Errorf("unable to apply seeds: %v", err)
+					if err := db.RunSeeds(ctx, opt); err != nil {
+						return err
 					}
 
 					log.Println("Seeds done!")
@@ -295,6 +383,53 @@ func (f *FluxGo) RunMigrations(ctx context.Context, opt DatabaseMigrationsOption
 	return fx.New(opts...).Start(ctx)
 }
 
+// Migrate opens a migrator on the primary database for opt.Dir and runs fn with it.
+func (d *Database) Migrate(opt DatabaseMigrationsOptions, fn func(migrator *migrate.Migrate) error) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error to get pwd: %v", err)
+	}
+
+	joined := path.Join(wd, opt.Dir)
+	migrationsDirFile := "file://" + joined
+
+	var postgresConfig = opt.Config
+	if postgresConfig == nil {
+		postgresConfig = &postgres.Config{}
+	}
+
+	driver, err := postgres.WithInstance(d.WriteDB().DB, postgresConfig)
+	if err != nil {
+		return fmt.Errorf("error to get pg driver: %v", err)
+	}
+
+	migrator, err := migrate.NewWithDatabaseInstance(migrationsDirFile, "postgres", driver)
+	if err != nil {
+		return fmt.Errorf("unable to create migration: %v", err)
+	}
+	defer func() {
+		if err1, err2 := migrator.Close(); err1 != nil || err2 != nil {
+			log.Printf("error closing migrator: %v", err1)
+			log.Printf("error closing migrator: %v", err2)
+		}
+	}()
+
+	return fn(migrator)
+}
+
+// RunSeeds executes opt.Seeds on the primary database.
+func (d *Database) RunSeeds(ctx context.Context, opt DatabaseMigrationsOptions) error {
+	if opt.Seeds == nil {
+		return nil
+	}
+
+	if _, err := d.WriteDB().ExecContext(ctx, *opt.Seeds); err != nil {
+		return fmt.Errorf("unable to apply seeds: %v", err)
+	}
+
+	return nil
+}
+
 func (d *Database) StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, Span) {
 	return d.apm.StartSpan(ctx, name, opts...)
 }
@@ -0,0 +1,105 @@
+package fluxgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/fx"
+)
+
+// Dependency names a subsystem added to FluxGo, used by modules to declare prerequisites.
+type Dependency string
+
+const (
+	DependencyHttp       Dependency = "http"
+	DependencyGrpc       Dependency = "grpc"
+	DependencyKafka      Dependency = "kafka"
+	DependencyCron       Dependency = "cron"
+	DependencyTools      Dependency = "tools"
+	DependencyDatabase   Dependency = "database"
+	DependencyRedis      Dependency = "redis"
+	DependencyApm        Dependency = "apm"
+	DependencyMetrics    Dependency = "metrics"
+	DependencyLogger     Dependency = "logger"
+	DependencyPrometheus Dependency = "prometheus"
+)
+
+var dependencySetup = map[Dependency]string{
+	DependencyHttp:       "AddHttp",
+	DependencyGrpc:       "AddGrpc",
+	DependencyKafka:      "AddKafka",
+	DependencyCron:       "AddCron",
+	DependencyTools:      "AddTools",
+	DependencyDatabase:   "AddDatabase",
+	DependencyRedis:      "AddRedis",
+	DependencyApm:        "AddApm",
+	DependencyMetrics:    "AddMetrics",
+	DependencyLogger:     "ConfigLogger",
+	DependencyPrometheus: "AddPrometheus",
+}
+
+// dependencyTypes are the types whose value, given to AddSupply or AddReplace, stands in
+// for the subsystem, e.g. a fake *Redis in tests.
+var dependencyTypes = map[Dependency]reflect.Type{
+	DependencyHttp:       reflect.TypeFor[*Http](),
+	DependencyGrpc:       reflect.TypeFor[*Grpc](),
+	DependencyKafka:      reflect.TypeFor[*Kafka](),
+	DependencyCron:       reflect.TypeFor[*Cron](),
+	DependencyTools:      reflect.TypeFor[*Tools](),
+	DependencyDatabase:   reflect.TypeFor[*Database](),
+	DependencyRedis:      reflect.TypeFor[*Redis](),
+	DependencyApm:        reflect.TypeFor[*Apm](),
+	DependencyMetrics:    reflect.TypeFor[*Metrics](),
+	DependencyLogger:     reflect.TypeFor[*Logger](),
+	DependencyPrometheus: reflect.TypeFor[*Prometheus](),
+}
+
+// markSupplied marks the subsystems whose type is among values as provided.
+func (f *FluxGo) markSupplied(values []interface{}) {
+	for _, value := range values {
+		for dep, typ := range dependencyTypes {
+			if reflect.TypeOf(value) == typ {
+				f.markProvided(dep)
+			}
+		}
+	}
+}
+
+func (f *FluxGo) markProvided(dep Dependency) {
+	if f.provided == nil {
+		f.provided = map[Dependency]bool{}
+	}
+	f.provided[dep] = true
+}
+
+// HasDependency reports whether the subsystem was added to the application, or a value
+// of its type was given to AddSupply or AddReplace.
+func (f *FluxGo) HasDependency(dep Dependency) bool {
+	return f.provided[dep]
+}
+
+// checkRequirements fails the application when a module is wired without its prerequisites.
+func (f *FluxGo) checkRequirements(m *FluxModule) fx.Option {
+	missing := []string{}
+	seen := map[Dependency]bool{}
+
+	for _, dep := range m.requirements(f) {
+		if seen[dep] || f.HasDependency(dep) {
+			continue
+		}
+		seen[dep] = true
+
+		if setup, ok := dependencySetup[dep]; ok {
+			missing = append(missing, fmt.Sprintf("%s (call FluxGo.%s)", dep, setup))
+		} else {
+			missing = append(missing, fmt.Sprintf("%s (call FluxGo.Use with the plugin)", dep))
+		}
+	}
+
+	if len(missing) == 0 {
+		return fx.Options()
+	}
+
+	return fx.Error(fmt.Errorf("module %q is missing required dependencies: %s", m.Name, strings.Join(missing, ", ")))
+}
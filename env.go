@@ -1,57 +1,288 @@
 package fluxgo
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 
+	"github.com/BurntSushi/toml"
 	"github.com/caarlos0/env/v11"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
 type EnvOptions struct {
+	// LoadFromFile is a dotenv file searched in the working directory and up to ten parents.
+	// Its values never override variables already set in the process environment.
 	LoadFromFile *string
-	Validate     bool
+	// ConfigFile is a base YAML, JSON or TOML file searched like LoadFromFile. Nested keys are
+	// flattened to env names, e.g. database.host becomes DATABASE_HOST. An overlay named after
+	// Env.Env (config.production.yaml next to config.yaml) is merged on top when present.
+	ConfigFile string
+	// Secrets resolves string fields tagged `secret:"db/password"`, overriding their env value.
+	// Without a provider the tag is ignored and the env value is kept. Tags are resolved
+	// once, while parsing; read values that rotate through FluxGo.AddSecrets instead.
+	Secrets  SecretProvider
+	Validate bool
 }
 
+// ParseEnv parses T like ParseEnvE and exits the process when the configuration is invalid.
 func ParseEnv[T any](opts EnvOptions) T {
+	config, err := ParseEnvE[T](opts)
+	if err != nil {
+		log.Fatal("Error to parse env: ", err)
+	}
+
+	return config
+}
+
+// ParseEnvE loads T from, in increasing precedence: ConfigFile, its environment overlay,
+// the dotenv file and the process environment. A FOO_FILE key provides FOO from the content
+// of the file it points to (Docker/Kubernetes secrets) and overrides FOO within the same layer.
+// The returned error lists every field that failed to parse or validate.
+func ParseEnvE[T any](opts EnvOptions) (T, error) {
+	var config T
+
 	if opts.LoadFromFile != nil {
-		path := *opts.LoadFromFile
-		// found := false
-		for i := 0; i < 10; i++ {
-			if _, err := os.Stat(path); err != nil {
-				path = "../" + path
-				continue
+		if path, found := findFile(*opts.LoadFromFile); found {
+			if err := loadDotenv(path); err != nil {
+				return config, err
+			}
+		}
+	}
+
+	fields, err := env.GetFieldParams(&config)
+	if err != nil {
+		return config, err
+	}
+	keys := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keys[field.Key] = true
+	}
+
+	environment, err := resolveFileKeys(envToMap(os.Environ()), keys)
+	if err != nil {
+		return config, err
+	}
+
+	if opts.ConfigFile != "" {
+		merged, err := loadConfigLayers(opts.ConfigFile, environment, keys)
+		if err != nil {
+			return config, err
+		}
+		for key, value := range environment {
+			merged[key] = value
+		}
+		environment = merged
+	}
+
+	if err := env.ParseWithOptions(&config, env.Options{Environment: environment}); err != nil {
+		var aggregate env.AggregateError
+		if errors.As(err, &aggregate) {
+			return config, fmt.Errorf("invalid environment: %w", errors.Join(aggregate.Errors...))
+		}
+		return config, fmt.Errorf("invalid environment: %w", err)
+	}
+
+	if opts.Secrets != nil {
+		if errs := resolveSecretTags(context.Background(), reflect.ValueOf(&config), opts.Secrets); len(errs) > 0 {
+			return config, fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+		}
+	}
+
+	if opts.Validate {
+		if errs := validator.New().Struct(config); errs != nil {
+			var validationErrors validator.ValidationErrors
+			if !errors.As(errs, &validationErrors) {
+				return config, fmt.Errorf("invalid environment: %w", errs)
 			}
-			// found = true
 
-			if err := godotenv.Load(path); err != nil {
-				log.Printf("Error loading %s file: %v", *opts.LoadFromFile, err)
+			fieldErrors := make([]error, 0, len(validationErrors))
+			for _, fieldErr := range validationErrors {
+				fieldErrors = append(fieldErrors, fmt.Errorf("%s: failed on '%s' validation", fieldErr.Namespace(), fieldErr.Tag()))
 			}
-			break
+			return config, fmt.Errorf("invalid environment: %w", errors.Join(fieldErrors...))
 		}
-		// if !found {
-		// 	log.Fatalf("File not found for parse: %s", *opts.LoadFromFile)
-		// }
 	}
 
-	config, err := env.ParseAs[T]()
+	return config, nil
+}
+
+// dotenvKeys tracks the variables set from dotenv files so a reload can refresh them
+// while variables that came from the real process environment keep precedence.
+var dotenvKeys = struct {
+	sync.Mutex
+	keys map[string]bool
+}{keys: map[string]bool{}}
+
+func loadDotenv(path string) error {
+	values, err := godotenv.Read(path)
 	if err != nil {
-		log.Fatal("Error to parse env:", err)
+		return fmt.Errorf("failed to load %s: %w", path, err)
 	}
 
-	if opts.Validate {
-		validate := validator.New()
-		if errs := validate.Struct(config); errs != nil {
-			log.Fatal("Invalid environment ", errs)
+	dotenvKeys.Lock()
+	defer dotenvKeys.Unlock()
+
+	for key, value := range values {
+		if _, exists := os.LookupEnv(key); exists && !dotenvKeys.keys[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
 		}
+		dotenvKeys.keys[key] = true
 	}
 
-	return config
+	return nil
+}
+
+// findFile looks for path in the working directory and up to ten parent directories.
+func findFile(path string) (string, bool) {
+	for i := 0; i < 10; i++ {
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		path = "../" + path
+	}
+
+	return "", false
+}
+
+// loadConfigLayers reads the base config file and the overlay for the current environment.
+// The environment name comes from ENV in the process environment, or else from the base file.
+func loadConfigLayers(file string, environment map[string]string, keys map[string]bool) (map[string]string, error) {
+	path, found := findFile(file)
+	if !found {
+		return nil, fmt.Errorf("config file not found: %s", file)
+	}
+
+	merged, err := readConfigFile(path, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	name := environment["ENV"]
+	if name == "" {
+		name = merged["ENV"]
+	}
+	if name == "" {
+		return merged, nil
+	}
+
+	ext := filepath.Ext(path)
+	overlayPath := strings.TrimSuffix(path, ext) + "." + name + ext
+	if _, err := os.Stat(overlayPath); err != nil {
+		return merged, nil
+	}
+
+	overlay, err := readConfigFile(overlayPath, keys)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+
+	return merged, nil
+}
+
+func readConfigFile(path string, keys map[string]bool) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	data := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	case ".json":
+		err = json.Unmarshal(content, &data)
+	case ".toml":
+		err = toml.Unmarshal(content, &data)
+	default:
+		return nil, fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flattenConfig("", data, values)
+
+	return resolveFileKeys(values, keys)
+}
+
+// flattenConfig turns nested config keys into env names: {"database": {"host": x}} -> DATABASE_HOST.
+// Lists are joined with commas, matching the default envSeparator.
+func flattenConfig(prefix string, data map[string]interface{}, out map[string]string) {
+	replacer := strings.NewReplacer("-", "_", ".", "_")
+
+	for key, value := range data {
+		name := strings.ToUpper(replacer.Replace(key))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenConfig(name, v, out)
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[name] = strings.Join(items, ",")
+		case nil:
+			out[name] = ""
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// resolveFileKeys replaces FOO with the content of the file named by FOO_FILE.
+// Only keys declared by the target struct are resolved, so unrelated *_FILE variables are ignored.
+func resolveFileKeys(values map[string]string, keys map[string]bool) (map[string]string, error) {
+	for key, path := range values {
+		name, ok := strings.CutSuffix(key, "_FILE")
+		if !ok || path == "" || !keys[name] || keys[key] {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		values[name] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return values, nil
+}
+
+func envToMap(environ []string) map[string]string {
+	values := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			values[key] = value
+		}
+	}
+
+	return values
 }
 
 type Env struct {
-	Env string `env:"ENV"`
+	Env   string   `env:"ENV"`
+	Roles []string `env:"FLUXGO_ROLES" envSeparator:","`
 }
 
 func (e Env) IsProduction() bool {
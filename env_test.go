@@ -0,0 +1,99 @@
+package fluxgo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envTestConfig struct {
+	Env      string   `env:"ENV"`
+	Name     string   `env:"APP_NAME" validate:"required"`
+	Hosts    []string `env:"HOSTS"`
+	Password string   `env:"DATABASE_PASSWORD"`
+	Port     int      `env:"DATABASE_PORT" validate:"required"`
+}
+
+func writeEnvTestFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestParseEnvE(t *testing.T) {
+	t.Run("Should merge config file, overlay, dotenv and env vars by precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		config := writeEnvTestFile(t, dir, "config.yaml", "env: staging\napp_name: base\nhosts: [a, b]\ndatabase:\n  port: 5432\n  password: base\n")
+		writeEnvTestFile(t, dir, "config.staging.yaml", "app_name: overlay\ndatabase:\n  password: overlay\n")
+		dotenv := writeEnvTestFile(t, dir, ".env", "DATABASE_PASSWORD=dotenv\n")
+		t.Setenv("DATABASE_PORT", "6543")
+		// The dotenv file sets DATABASE_PASSWORD in the process; Setenv restores it after the test.
+		t.Setenv("DATABASE_PASSWORD", "")
+		os.Unsetenv("DATABASE_PASSWORD")
+
+		cfg, err := ParseEnvE[envTestConfig](EnvOptions{ConfigFile: config, LoadFromFile: &dotenv, Validate: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "staging", cfg.Env)
+		assert.Equal(t, "overlay", cfg.Name)
+		assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		assert.Equal(t, "dotenv", cfg.Password)
+		assert.Equal(t, 6543, cfg.Port)
+	})
+
+	t.Run("Should read JSON and TOML config files", func(t *testing.T) {
+		dir := t.TempDir()
+		jsonFile := writeEnvTestFile(t, dir, "config.json", `{"app_name": "json", "database": {"port": 1}}`)
+		tomlFile := writeEnvTestFile(t, dir, "config.toml", "app_name = \"toml\"\n[database]\nport = 2\n")
+
+		cfg, err := ParseEnvE[envTestConfig](EnvOptions{ConfigFile: jsonFile})
+		assert.NoError(t, err)
+		assert.Equal(t, "json", cfg.Name)
+		assert.Equal(t, 1, cfg.Port)
+
+		cfg, err = ParseEnvE[envTestConfig](EnvOptions{ConfigFile: tomlFile})
+		assert.NoError(t, err)
+		assert.Equal(t, "toml", cfg.Name)
+		assert.Equal(t, 2, cfg.Port)
+	})
+
+	t.Run("Should resolve _FILE secrets", func(t *testing.T) {
+		secret := writeEnvTestFile(t, t.TempDir(), "password", "s3cret\n")
+		t.Setenv("APP_NAME", "app")
+		t.Setenv("DATABASE_PORT", "5432")
+		t.Setenv("DATABASE_PASSWORD", "plain")
+		t.Setenv("DATABASE_PASSWORD_FILE", secret)
+
+		cfg, err := ParseEnvE[envTestConfig](EnvOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cret", cfg.Password)
+	})
+
+	t.Run("Should list every invalid field", func(t *testing.T) {
+		t.Setenv("APP_NAME", "")
+		t.Setenv("DATABASE_PORT", "")
+
+		_, err := ParseEnvE[envTestConfig](EnvOptions{Validate: true})
+
+		assert.ErrorContains(t, err, "envTestConfig.Name: failed on 'required' validation")
+		assert.ErrorContains(t, err, "envTestConfig.Port: failed on 'required' validation")
+	})
+
+	t.Run("Should report values that cannot be parsed", func(t *testing.T) {
+		t.Setenv("DATABASE_PORT", "abc")
+
+		_, err := ParseEnvE[envTestConfig](EnvOptions{})
+
+		assert.ErrorContains(t, err, `parse error on field "Port"`)
+	})
+
+	t.Run("Should fail when the config file is missing", func(t *testing.T) {
+		_, err := ParseEnvE[envTestConfig](EnvOptions{ConfigFile: "missing-config.yaml"})
+
+		assert.ErrorContains(t, err, "config file not found")
+	})
+}
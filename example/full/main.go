@@ -7,5 +7,5 @@ import (
 func main() {
 	flux := module.Module()
 
-	flux.Run()
+	flux.Execute()
 }
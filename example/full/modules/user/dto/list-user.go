@@ -1,19 +1,14 @@
 package dto
 
 import (
+	fluxgo "github.com/MMortari/FluxGo"
 	"github.com/MMortari/FluxGo/example/full/shared/entities"
 )
 
-type PaginatedQuery struct {
-	Page     int `query:"page" validate:"min=1"`
-	PageSize int `query:"page_size" validate:"min=1,max=100"`
-}
-
+// ListUserReq pages with ?page and ?limit (formerly ?page_size); the route caps limit at 100.
 type ListUserReq struct {
-	PaginatedQuery
+	fluxgo.PageRequest
 	IdUser *string `query:"id_user" jsonschema:"title=Identificador do usuário"`
 	Name   *string `query:"name" jsonschema:"title=Nome do usuário,default=João da Silva,maxLength=150"`
 }
-type ListUserRes struct {
-	Data []entities.User `json:"data"`
-}
+type ListUserRes = fluxgo.Paginated[entities.User]
@@ -33,12 +33,12 @@ func HandlerListUserStart(repository *repositories.UserRepository, tools *fluxgo
 }
 
 func (h *HandlerListUser) Execute(ctx c.Context, data *dto.ListUserReq) (*dto.ListUserRes, *fluxgo.GlobalError) {
-	users, err := h.repository.ListUser(ctx, &repositories.UserFilter{IdUser: data.IdUser, Name: data.Name})
+	users, err := h.repository.ListUser(ctx, &repositories.UserFilter{IdUser: data.IdUser, Name: data.Name}, data.PageRequest)
 	if err != nil {
 		return nil, fluxgo.ErrorInternalError("Error to list user")
 	}
 
-	return &dto.ListUserRes{Data: users}, nil
+	return users, nil
 }
 
 func (h *HandlerListUser) Name() string {
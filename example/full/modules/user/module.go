@@ -25,6 +25,7 @@ func Module() *fluxgo.FluxModule {
 			fluxgo.GET[handlers.HandlerListUser]("/public", "/user", fluxgo.RouteIncome{
 				Entity:     dto.ListUserReq{},
 				FromQuery:  true,
+				Pagination: &fluxgo.PaginationOptions{SortFields: []string{"name"}, DefaultSort: "name", MaxLimit: 100},
 				CacheTTL:   time.Hour,
 				Permission: &fluxgo.RoutePermission{Action: "read", Subject: "user"},
 				Doc: &fluxgo.RouteDoc{
@@ -33,11 +34,11 @@ func Module() *fluxgo.FluxModule {
 					OkResponse:  dto.ListUserRes{},
 				},
 			}),
-			fluxgo.GET[handlers.HandlerGetUser]("/public", "/user/:id_user", fluxgo.RouteIncome{Entity: dto.GetUserReq{}, CacheTTL: time.Hour}),
+			fluxgo.UseCaseHttp[handlers.HandlerGetUser, dto.GetUserReq, dto.GetUserRes]("/public", "GET", "/user/:id_user", fluxgo.RouteIncome{CacheTTL: time.Hour}),
 			fluxgo.PUT[handlers.HandlerUpdateUser]("/public", "/user/:id_user", fluxgo.RouteIncome{Entity: dto.UpdateUserReq{}, CacheTTL: time.Hour, Doc: &fluxgo.RouteDoc{Summary: "Atualiza informações do usuário", Description: "Atualiza informações do usuário com base no ID fornecido", OkResponse: dto.UpdateUserRes{}}}),
-			fluxgo.POST[handlers.HandlerGetUser]("/internal", "/refresh", fluxgo.RouteIncome{Entity: dto.GetUserReq{}, CacheInvalidate: []string{"/public/user"}}),
+			fluxgo.UseCaseHttp[handlers.HandlerGetUser, dto.GetUserReq, dto.GetUserRes]("/internal", "POST", "/refresh", fluxgo.RouteIncome{CacheInvalidate: []string{"/public/user"}}),
 			fluxgo.TopicDef[handlers.HandlerGetUser]("TEST"),
-			fluxgo.ToolDef[handlers.HandlerGetUser](),
+			fluxgo.UseCaseTool[handlers.HandlerGetUser, dto.GetUserReq, dto.GetUserRes]("HandlerGetUser", "Tool to get user information"),
 			fluxgo.CronDef[handlers.HandlerGetUser]("* * * * *"),
 			fluxgo.GrpcDef[handlers.HandlerUserGrpc](),
 		)
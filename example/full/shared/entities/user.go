@@ -1,7 +1,7 @@
 package entities
 
 type User struct {
-	ID   string `json:"id" jsonschema:"title=Identificador do usuário"`
+	ID   string `db:"id_user" json:"id" jsonschema:"title=Identificador do usuário"`
 	Name string `json:"name" jsonschema:"title=Nome"`
 }
 
@@ -10,5 +10,5 @@ func (u User) TableName() string {
 }
 
 func (u User) PrimaryKey() string {
-	return "id"
+	return "id_user"
 }
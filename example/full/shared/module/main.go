@@ -49,6 +49,10 @@ func Module() *fluxgo.FluxGo {
 		AddHealthRoutes: true,
 		Permissions:     getPermissions(),
 		Swagger:         &fluxgo.SwaggerOptions{Description: "API de exemplo do FluxGo"},
+		Inventory: &fluxgo.InventoryOptions{
+			Permission: &fluxgo.RoutePermission{Action: "read", Subject: "inventory"},
+			Middleware: []fiber.Handler{middlewareExample()},
+		},
 	}, func(data fluxgo.HttpConfigData) {
 		data.CreateRouter("/public", fluxgo.WithMiddleware(middlewareExample()))
 		data.CreateRouter("/internal",
@@ -63,6 +67,11 @@ func Module() *fluxgo.FluxGo {
 	flux.AddTools()
 	flux.AddGrpc(fluxgo.GrpcOptions{Port: 50051, Reflection: true})
 
+	flux.AddMigrations(fluxgo.DatabaseMigrationsOptions{
+		Dir:   "shared/database/migrations",
+		Seeds: fluxgo.Pointer(`INSERT INTO "user" (name) VALUES ('John Doe');`),
+	})
+
 	flux.AddDependency(repositories.UserRepositoryStart)
 
 	flux.AddModule(user.Module())
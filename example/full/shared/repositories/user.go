@@ -3,6 +3,8 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	fluxgo "github.com/MMortari/FluxGo"
 	"github.com/MMortari/FluxGo/example/full/shared/entities"
@@ -22,7 +24,7 @@ func (r *UserRepository) GetUser(ctx context.Context) (*entities.User, error) {
 
 	var user entities.User
 
-	err := r.DB.ReadOnlyDB().GetContext(ctx, &user, "SELECT '299f3dcd-42f3-46c1-89d5-603c78a78f50' as id, 'John' AS name")
+	err := r.DB.ReadOnlyDB().GetContext(ctx, &user, "SELECT '299f3dcd-42f3-46c1-89d5-603c78a78f50' as id_user, 'John' AS name")
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -39,17 +41,17 @@ type UserFilter struct {
 	Name   *string
 }
 
-func (r *UserRepository) ListUser(ctx context.Context, filter *UserFilter) ([]entities.User, error) {
-	ctx, span := r.StartSpan(ctx)
-	defer span.End()
-
-	query := "SELECT '299f3dcd-42f3-46c1-89d5-603c78a78f50' as id, 'John' AS name"
-
-	users := make([]entities.User, 0)
-	if err := r.DB.ReadOnlyDB().SelectContext(ctx, &users, query); err != nil {
-		span.SetError(err)
-		return nil, err
+func (r *UserRepository) ListUser(ctx context.Context, filter *UserFilter, page fluxgo.PageRequest) (*fluxgo.Paginated[entities.User], error) {
+	conditions := []string{}
+	args := []any{}
+	if filter.IdUser != nil {
+		args = append(args, *filter.IdUser)
+		conditions = append(conditions, fmt.Sprintf("id_user = $%d", len(args)))
+	}
+	if filter.Name != nil {
+		args = append(args, "%"+*filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
 	}
 
-	return users, nil
+	return r.FindPage(ctx, page, strings.Join(conditions, " AND "), args...)
 }
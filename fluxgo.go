@@ -2,7 +2,10 @@ package fluxgo
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,14 +17,30 @@ type FluxGo struct {
 	FluxGoConfig
 	cleanName string
 
-	otel *Otel
-	db   *Database
+	otel     *Otel
+	db       *Database
+	health   *Health
+	shutdown shutdown
+	sources  inventorySources
 
 	dependencies []fx.Option
+	resources    []fx.Option
 	invokes      []fx.Option
 	replaces     []fx.Option
 	supplies     []fx.Option
 	modules      []*FluxModule
+
+	provided    map[Dependency]bool
+	secrets     *Secrets
+	plugins     []Plugin
+	pluginsOnce sync.Once
+	pluginsInit bool
+	pluginOpts  []fx.Option
+	validator   *Validator
+	commands    []Command
+	commandMode bool
+	migrations  *DatabaseMigrationsOptions
+	stdout      io.Writer
 }
 type FluxGoConfig struct {
 	Name         string
@@ -30,6 +49,10 @@ type FluxGoConfig struct {
 	FullDebugger bool
 	Env          *Env
 	Otel         *OtelOptions
+	Shutdown     *ShutdownOptions
+	// Roles limits the subsystems activated by this process. Falls back to FLUXGO_ROLES;
+	// unknown roles fail the application at startup.
+	Roles []Role
 }
 
 func New(config FluxGoConfig) *FluxGo {
@@ -37,6 +60,7 @@ func New(config FluxGoConfig) *FluxGo {
 		FluxGoConfig: config,
 		cleanName:    strings.ReplaceAll(strings.ToLower(config.Name), " ", "_"),
 		dependencies: []fx.Option{},
+		resources:    []fx.Option{},
 		invokes:      []fx.Option{},
 		replaces:     []fx.Option{},
 		supplies:     []fx.Option{},
@@ -46,12 +70,24 @@ func New(config FluxGoConfig) *FluxGo {
 		env := ParseEnv[Env](EnvOptions{})
 		init.Env = &env
 	}
+	var rolesErr error
+	if len(init.Roles) == 0 {
+		init.Roles, rolesErr = parseRoles(init.Env.Roles)
+	} else {
+		rolesErr = validateRoles(init.Roles)
+	}
 
 	init.dependencies = append(init.dependencies, fx.Provide(func() *FluxGo { return &init }))
+	if rolesErr != nil {
+		init.dependencies = append(init.dependencies, fx.Error(fmt.Errorf("roles: %w", rolesErr)))
+	}
 
 	init.db = &Database{dbs: make(map[string]*databaseData)}
 	init.dependencies = append(init.dependencies, fx.Provide(func() *Database { return init.db }))
 
+	init.health = newHealth()
+	init.dependencies = append(init.dependencies, fx.Provide(func() *Health { return init.health }))
+
 	if init.Otel != nil {
 		init.addOtel(*init.Otel)
 	} else {
@@ -83,12 +119,14 @@ func (f *FluxGo) AddInvoke(constructors ...interface{}) *FluxGo {
 func (f *FluxGo) AddReplace(constructors ...interface{}) *FluxGo {
 	opt := fx.Replace(constructors...)
 	f.replaces = append(f.replaces, opt)
+	f.markSupplied(constructors)
 
 	return f
 }
 func (f *FluxGo) AddSupply(constructors ...interface{}) *FluxGo {
 	opt := fx.Supply(constructors...)
 	f.supplies = append(f.supplies, opt)
+	f.markSupplied(constructors)
 
 	return f
 }
@@ -100,16 +138,25 @@ func (f *FluxGo) AddModule(mod *FluxModule) *FluxGo {
 }
 
 func (f *FluxGo) GetFxConfig() []fx.Option {
-	full := append(f.dependencies, f.invokes...)
+	full := append([]fx.Option{}, f.dependencies...)
+	full = append(full, f.resources...)
+	full = append(full, f.pluginOptions()...)
+	full = append(full, f.drainOption())
+	full = append(full, f.moduleHooksOption())
+	full = append(full, f.invokes...)
 	full = append(full, f.replaces...)
 	full = append(full, f.supplies...)
 
 	modules := []fx.Option{}
 	for _, module := range f.modules {
-		modules = append(modules, module.toFx())
+		modules = append(modules, f.checkRequirements(module), module.toFx(f))
 	}
 	full = append(full, modules...)
+	full = append(full, f.preStopOption())
 
+	if f.Shutdown != nil && f.Shutdown.Timeout > 0 {
+		full = append(full, fx.StopTimeout(f.Shutdown.Timeout))
+	}
 	if !f.FullDebugger {
 		full = append(full, fx.NopLogger)
 	}
@@ -141,3 +188,8 @@ func (f *FluxGo) Log(key, message string) {
 		fmt.Printf("%s [%s] %s\n", time.Now().Format(time.DateTime), key, message)
 	}
 }
+
+// LogError prints failures that would otherwise go unnoticed to stderr, even without Debugger.
+func (f *FluxGo) LogError(key, message string) {
+	fmt.Fprintf(os.Stderr, "%s [%s] %s\n", time.Now().Format(time.DateTime), key, message)
+}
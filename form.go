@@ -0,0 +1,222 @@
+package fluxgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const formFilesLocal = "fluxgo_form_files"
+
+var (
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeaderSliceType = reflect.TypeFor[[]*multipart.FileHeader]()
+	multipartFileType   = reflect.TypeFor[multipart.File]()
+)
+
+// FormOptions limits the uploads of a route reading FromForm.
+//
+// Fiber rejects bodies above FiberConfig.BodyLimit before the route runs, so raise it for
+// large uploads. With FiberConfig.StreamRequestBody the body is decoded while it is received
+// and large files are spooled to disk instead of memory.
+type FormOptions struct {
+	// MaxSize of the request body in bytes. Default: FiberConfig.BodyLimit
+	MaxSize int64
+	// MaxFileSize of each file in bytes. Default: unlimited
+	MaxFileSize int64
+	// MaxFiles of the request. Default: unlimited
+	MaxFiles int
+	// AllowedTypes are the MIME types accepted for files, detected from their content rather than
+	// trusted from the client; a type may end with /*, e.g. "image/*". Default: every type
+	AllowedTypes []string
+}
+
+// parseForm binds a multipart/form-data or urlencoded body into data. Form fields are bound by
+// their form tag; file fields may be *multipart.FileHeader, []*multipart.FileHeader, or a
+// multipart.File (or io.Reader) opened for the handler and closed once it returns. The
+// Content-Type header of every file is replaced with the type detected from its content.
+func (i *RouteIncome) parseForm(c *fiber.Ctx, data any) *GlobalError {
+	opt := FormOptions{}
+	if i.Form != nil {
+		opt = *i.Form
+	}
+
+	if opt.MaxSize > 0 && int64(c.Request().Header.ContentLength()) > opt.MaxSize {
+		return errorPayloadTooLarge(fmt.Sprintf("Request body exceeds %d bytes", opt.MaxSize), "error.request_too_large")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if mediaType != fiber.MIMEMultipartForm && mediaType != fiber.MIMEApplicationForm {
+		return errorUnsupportedMediaType("Expected multipart/form-data or application/x-www-form-urlencoded", "error.unsupported_media_type")
+	}
+
+	if err := c.BodyParser(data); err != nil {
+		return ErrorBadRequest("Error parsing form", "error.internal")
+	}
+	if mediaType != fiber.MIMEMultipartForm {
+		return nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return ErrorBadRequest("Error parsing form", "error.internal")
+	}
+
+	if err := checkFormFiles(form, opt); err != nil {
+		return err
+	}
+
+	return bindFormFiles(c, form, reflect.ValueOf(data).Elem())
+}
+
+func checkFormFiles(form *multipart.Form, opt FormOptions) *GlobalError {
+	count, size := 0, int64(0)
+	for _, files := range form.File {
+		for _, file := range files {
+			count++
+			size += file.Size
+
+			if opt.MaxFileSize > 0 && file.Size > opt.MaxFileSize {
+				return errorPayloadTooLarge(fmt.Sprintf("File %s exceeds %d bytes", file.Filename, opt.MaxFileSize), "error.file_too_large")
+			}
+
+			contentType, err := detectContentType(file)
+			if err != nil {
+				return ErrorBadRequest(fmt.Sprintf("Error reading file %s", file.Filename), "error.internal")
+			}
+			if !allowedContentType(opt.AllowedTypes, contentType) {
+				return errorUnsupportedMediaType(fmt.Sprintf("File %s has unsupported type %s", file.Filename, contentType), "error.unsupported_file_type")
+			}
+			file.Header.Set(fiber.HeaderContentType, contentType)
+		}
+	}
+
+	if opt.MaxFiles > 0 && count > opt.MaxFiles {
+		return ErrorBadRequest(fmt.Sprintf("At most %d files are accepted", opt.MaxFiles), "error.too_many_files")
+	}
+	// Without a Content-Length, e.g. on a chunked streamed body, the size is only known now.
+	if opt.MaxSize > 0 && size > opt.MaxSize {
+		return errorPayloadTooLarge(fmt.Sprintf("Request body exceeds %d bytes", opt.MaxSize), "error.request_too_large")
+	}
+
+	return nil
+}
+
+// detectContentType sniffs the MIME type of file from its first 512 bytes.
+func detectContentType(file *multipart.FileHeader) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+
+	return mediaType, nil
+}
+
+func allowedContentType(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, pattern := range allowed {
+		if prefix, found := strings.CutSuffix(pattern, "/*"); found {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+		} else if pattern == contentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bindFormFiles sets the file fields of entity from the files of form.
+func bindFormFiles(c *fiber.Ctx, form *multipart.Form, entity reflect.Value) *GlobalError {
+	if entity.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range reflect.VisibleFields(entity.Type()) {
+		name := tagValue(field, "form")
+		files := form.File[name]
+		if name == "" || len(files) == 0 || !field.IsExported() {
+			continue
+		}
+
+		value, err := entity.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+		switch {
+		case field.Type == fileHeaderType:
+			value.Set(reflect.ValueOf(files[0]))
+		case field.Type == fileHeaderSliceType:
+			value.Set(reflect.ValueOf(files))
+		case field.Type.Kind() == reflect.Interface && multipartFileType.Implements(field.Type):
+			reader, err := files[0].Open()
+			if err != nil {
+				return ErrorBadRequest(fmt.Sprintf("Error reading file %s", files[0].Filename), "error.internal")
+			}
+			trackFormFile(c, reader)
+			value.Set(reflect.ValueOf(reader))
+		}
+	}
+
+	return nil
+}
+
+// trackFormFile keeps reader to be closed by closeFormFiles once the handler returns.
+func trackFormFile(c *fiber.Ctx, reader io.Closer) {
+	files, _ := c.Locals(formFilesLocal).([]io.Closer)
+	c.Locals(formFilesLocal, append(files, reader))
+}
+
+func formFiles(c *fiber.Ctx) []io.Closer {
+	files, _ := c.Locals(formFilesLocal).([]io.Closer)
+
+	return files
+}
+
+func closeFormFiles(c *fiber.Ctx) {
+	closeFiles(formFiles(c))
+}
+
+func closeFiles(files []io.Closer) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
+
+func errorPayloadTooLarge(message, code string) *GlobalError {
+	return &GlobalError{
+		Message: message,
+		Code:    code,
+		Status:  fiber.StatusRequestEntityTooLarge,
+		Success: false,
+	}
+}
+
+func errorUnsupportedMediaType(message, code string) *GlobalError {
+	return &GlobalError{
+		Message: message,
+		Code:    code,
+		Status:  fiber.StatusUnsupportedMediaType,
+		Success: false,
+	}
+}
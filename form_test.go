@@ -0,0 +1,136 @@
+package fluxgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var formTestPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type formTestUpload struct {
+	Title       string                  `form:"title" validate:"required"`
+	File        *multipart.FileHeader   `form:"file"`
+	Content     io.Reader               `form:"file"`
+	Attachments []*multipart.FileHeader `form:"attachments"`
+}
+
+type formTestHandler struct{}
+
+func (h *formTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	upload := income.(*formTestUpload)
+
+	content, _ := io.ReadAll(upload.Content)
+
+	return &GlobalResponse{Status: 200, Content: fiber.Map{
+		"title":       upload.Title,
+		"filename":    upload.File.Filename,
+		"type":        upload.File.Header.Get(fiber.HeaderContentType),
+		"size":        len(content),
+		"attachments": len(upload.Attachments),
+	}}, nil
+}
+
+type formTestFile struct {
+	field, name string
+	content     []byte
+}
+
+func formTestRequest(t *testing.T, http *Http, path string, fields map[string]string, files ...formTestFile) (int, map[string]any) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		assert.NoError(t, writer.WriteField(name, value))
+	}
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.name)
+		assert.NoError(t, err)
+		_, _ = part.Write(file.content)
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	res, err := http.GetApp().Test(req)
+	assert.NoError(t, err)
+
+	parsed := map[string]any{}
+	_ = json.NewDecoder(res.Body).Decode(&parsed)
+	return res.StatusCode, parsed
+}
+
+func TestFormUpload(t *testing.T) {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Swagger: &SwaggerOptions{}}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *formTestHandler { return &formTestHandler{} }).
+		Route(
+			POST[formTestHandler]("/api", "/documents", RouteIncome{
+				Entity: formTestUpload{}, FromForm: true, Validate: true,
+				Form: &FormOptions{MaxFileSize: 1024, MaxFiles: 2, AllowedTypes: []string{"image/*", "application/pdf"}},
+			}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should bind form fields, file headers and readers", func(t *testing.T) {
+		status, body := formTestRequest(t, http, "/api/documents", map[string]string{"title": "Logo"},
+			formTestFile{"file", "logo.png", formTestPNG},
+			formTestFile{"attachments", "scan.pdf", []byte("%PDF-1.4 scan")},
+		)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "Logo", body["title"])
+		assert.Equal(t, "logo.png", body["filename"])
+		assert.Equal(t, "image/png", body["type"])
+		assert.Equal(t, float64(len(formTestPNG)), body["size"])
+		assert.Equal(t, float64(1), body["attachments"])
+	})
+
+	t.Run("Should reject types detected from the content", func(t *testing.T) {
+		status, body := formTestRequest(t, http, "/api/documents", map[string]string{"title": "Logo"},
+			formTestFile{"file", "logo.png", []byte("plain text pretending to be an image")})
+		assert.Equal(t, 415, status)
+		assert.Equal(t, "error.unsupported_file_type", body["code"])
+	})
+
+	t.Run("Should limit the file size and count", func(t *testing.T) {
+		status, body := formTestRequest(t, http, "/api/documents", map[string]string{"title": "Logo"},
+			formTestFile{"file", "logo.png", append(formTestPNG, make([]byte, 2048)...)})
+		assert.Equal(t, 413, status)
+		assert.Equal(t, "error.file_too_large", body["code"])
+
+		status, body = formTestRequest(t, http, "/api/documents", map[string]string{"title": "Logo"},
+			formTestFile{"file", "logo.png", formTestPNG},
+			formTestFile{"attachments", "a.png", formTestPNG},
+			formTestFile{"attachments", "b.png", formTestPNG},
+		)
+		assert.Equal(t, 400, status)
+		assert.Equal(t, "error.too_many_files", body["code"])
+	})
+
+	t.Run("Should reject bodies that are not forms", func(t *testing.T) {
+		status, body := RunTestRequest(http, "POST", "/api/documents", map[string]string{"title": "Logo"}, nil)
+		assert.Equal(t, 415, status)
+		assert.Equal(t, "error.unsupported_media_type", body["code"])
+	})
+
+	t.Run("Should document a multipart/form-data body", func(t *testing.T) {
+		spec := http.OpenAPISpec("")
+		operation := spec["paths"].(map[string]any)["/api/documents"].(map[string]any)["post"].(map[string]any)
+		content := operation["requestBody"].(map[string]any)["content"].(map[string]any)
+		schema := content["multipart/form-data"].(map[string]any)["schema"].(map[string]any)
+		properties := schema["properties"].(map[string]any)
+
+		assert.Equal(t, map[string]any{"type": "string", "format": "binary"}, properties["file"])
+		assert.Equal(t, "array", properties["attachments"].(map[string]any)["type"])
+		assert.Equal(t, "string", properties["title"].(map[string]any)["type"])
+		assert.Equal(t, []string{"title"}, schema["required"])
+	})
+}
@@ -2,8 +2,10 @@ package fluxgo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/fx"
@@ -22,8 +24,9 @@ type GrpcOptions struct {
 
 // Grpc wraps a gRPC server managed by FluxGo.
 type Grpc struct {
-	server *grpc.Server
-	opts   GrpcOptions
+	server  *grpc.Server
+	opts    GrpcOptions
+	serving atomic.Bool
 }
 
 // GrpcClientOptions configures an outgoing gRPC client connection.
@@ -52,6 +55,8 @@ type GrpcHandlerInterface interface {
 // AddGrpc registers a gRPC server with lifecycle management.
 // Handlers are registered via GrpcDef in each FluxModule.
 func (f *FluxGo) AddGrpc(opts GrpcOptions) *FluxGo {
+	f.markProvided(DependencyGrpc)
+
 	f.AddDependency(func() *Grpc {
 		serverOpts := []grpc.ServerOption{
 			grpc.StatsHandler(otelgrpc.NewServerHandler()),
@@ -70,10 +75,19 @@ func (f *FluxGo) AddGrpc(opts GrpcOptions) *FluxGo {
 			reflection.Register(server)
 		}
 
-		return &Grpc{server: server, opts: opts}
+		g := &Grpc{server: server, opts: opts}
+		f.sources.grpc = g
+
+		return g
 	})
 
 	f.AddInvoke(func(lc fx.Lifecycle, g *Grpc) error {
+		if !f.isServing(RoleGrpc) {
+			return nil
+		}
+
+		f.AddHealthCheck("grpc", g.Ping)
+
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				lis, err := net.Listen("tcp", fmt.Sprintf(":%d", g.opts.Port))
@@ -81,7 +95,10 @@ func (f *FluxGo) AddGrpc(opts GrpcOptions) *FluxGo {
 					return fmt.Errorf("failed to bind gRPC port %d: %w", g.opts.Port, err)
 				}
 
+				g.serving.Store(true)
 				go func() {
+					defer g.serving.Store(false)
+
 					if err := g.server.Serve(lis); err != nil && err != grpc.ErrServerStopped {
 						f.Log("GRPC", fmt.Sprintf("Server error: %v", err))
 					}
@@ -114,3 +131,12 @@ func (f *FluxGo) AddGrpc(opts GrpcOptions) *FluxGo {
 
 	return f
 }
+
+// Ping reports whether the gRPC server is currently serving.
+func (g *Grpc) Ping(_ context.Context) error {
+	if !g.serving.Load() {
+		return errors.New("grpc server is not serving")
+	}
+
+	return nil
+}
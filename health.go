@@ -0,0 +1,181 @@
+package fluxgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+
+	defaultHealthCheckTimeout = 5 * time.Second
+)
+
+// HealthCheck reports whether a dependency is usable. A nil error means healthy.
+type HealthCheck func(ctx context.Context) error
+
+type healthEntry struct {
+	name  string
+	check HealthCheck
+}
+
+// Health is the registry of named readiness checks exposed by /readyz and /health.
+type Health struct {
+	mu       sync.RWMutex
+	checks   []healthEntry
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+type HealthCheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+type HealthReport struct {
+	Status   string                       `json:"status"`
+	Draining bool                         `json:"draining,omitempty"`
+	Checks   map[string]HealthCheckResult `json:"checks"`
+}
+
+func newHealth() *Health {
+	return &Health{checks: make([]healthEntry, 0), timeout: defaultHealthCheckTimeout}
+}
+
+// AddHealthCheck registers a named check that is evaluated on every readiness probe.
+func (f *FluxGo) AddHealthCheck(name string, check HealthCheck) *FluxGo {
+	f.health.Register(name, check)
+
+	return f
+}
+
+// Register adds or replaces the check identified by name.
+func (h *Health) Register(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, entry := range h.checks {
+		if entry.name == name {
+			h.checks[i].check = check
+			return
+		}
+	}
+
+	h.checks = append(h.checks, healthEntry{name: name, check: check})
+}
+
+// SetDraining marks the instance as shutting down, failing every readiness probe.
+func (h *Health) SetDraining(draining bool) {
+	h.draining.Store(draining)
+}
+func (h *Health) IsDraining() bool {
+	return h.draining.Load()
+}
+
+// Names returns the registered check names in registration order.
+func (h *Health) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	names := make([]string, 0, len(h.checks))
+	for _, entry := range h.checks {
+		names = append(names, entry.name)
+	}
+
+	return names
+}
+
+// Check runs every registered check concurrently, each bounded by the registry timeout.
+func (h *Health) Check(ctx context.Context) HealthReport {
+	h.mu.RLock()
+	checks := make([]healthEntry, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	report := HealthReport{Status: HealthStatusUp, Checks: make(map[string]HealthCheckResult, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, entry := range checks {
+		wg.Add(1)
+		go func(entry healthEntry) {
+			defer wg.Done()
+
+			result := h.run(ctx, entry.check)
+
+			mu.Lock()
+			report.Checks[entry.name] = result
+			if result.Status != HealthStatusUp {
+				report.Status = HealthStatusDown
+			}
+			mu.Unlock()
+		}(entry)
+	}
+
+	wg.Wait()
+
+	if h.IsDraining() {
+		report.Status = HealthStatusDown
+		report.Draining = true
+	}
+
+	return report
+}
+
+func (h *Health) run(pCtx context.Context, check HealthCheck) (result HealthCheckResult) {
+	ctx, cancel := context.WithTimeout(pCtx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+
+		if r := recover(); r != nil {
+			result.Status = HealthStatusDown
+			result.Error = "health check panicked"
+		}
+	}()
+
+	if err := check(ctx); err != nil {
+		return HealthCheckResult{Status: HealthStatusDown, Error: err.Error()}
+	}
+
+	return HealthCheckResult{Status: HealthStatusUp}
+}
+
+func (r HealthReport) IsHealthy() bool {
+	return r.Status == HealthStatusUp
+}
+
+// FailedChecks returns the sorted names of the checks that are not up.
+func (r HealthReport) FailedChecks() []string {
+	failed := []string{}
+	for name, result := range r.Checks {
+		if result.Status != HealthStatusUp {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+
+	return failed
+}
+
+func (h *Health) fiberHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := h.Check(c.UserContext())
+
+		status := fiber.StatusOK
+		if !report.IsHealthy() {
+			status = fiber.StatusServiceUnavailable
+		}
+
+		return c.Status(status).JSON(report)
+	}
+}
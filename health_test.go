@@ -0,0 +1,89 @@
+package fluxgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	t.Run("Should report up when every check passes", func(t *testing.T) {
+		health := newHealth()
+		health.Register("first", func(ctx context.Context) error { return nil })
+		health.Register("second", func(ctx context.Context) error { return nil })
+
+		report := health.Check(context.Background())
+
+		assert.True(t, report.IsHealthy())
+		assert.Len(t, report.Checks, 2)
+		assert.Empty(t, report.FailedChecks())
+	})
+
+	t.Run("Should report down with the failing check error", func(t *testing.T) {
+		health := newHealth()
+		health.Register("ok", func(ctx context.Context) error { return nil })
+		health.Register("db", func(ctx context.Context) error { return errors.New("connection refused") })
+
+		report := health.Check(context.Background())
+
+		assert.False(t, report.IsHealthy())
+		assert.Equal(t, []string{"db"}, report.FailedChecks())
+		assert.Equal(t, "connection refused", report.Checks["db"].Error)
+		assert.Equal(t, HealthStatusUp, report.Checks["ok"].Status)
+	})
+
+	t.Run("Should fail checks that exceed the timeout", func(t *testing.T) {
+		health := newHealth()
+		health.timeout = 10 * time.Millisecond
+		health.Register("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		report := health.Check(context.Background())
+
+		assert.Equal(t, []string{"slow"}, report.FailedChecks())
+	})
+
+	t.Run("Should replace a check registered twice", func(t *testing.T) {
+		health := newHealth()
+		health.Register("db", func(ctx context.Context) error { return errors.New("down") })
+		health.Register("db", func(ctx context.Context) error { return nil })
+
+		assert.Equal(t, []string{"db"}, health.Names())
+		assert.True(t, health.Check(context.Background()).IsHealthy())
+	})
+
+	t.Run("Should answer 503 on readyz when a check fails", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddHttp(HttpOptions{AddHealthRoutes: true}, func(HttpConfigData) {})
+
+		healthy := true
+		flux.AddHealthCheck("dependency", func(ctx context.Context) error {
+			if !healthy {
+				return errors.New("unavailable")
+			}
+			return nil
+		})
+
+		_, http := flux.GetTestApp(t)
+
+		status, body := RunTestRequest(http, "GET", "/readyz", nil, nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, HealthStatusUp, body["status"])
+
+		healthy = false
+
+		status, body = RunTestRequest(http, "GET", "/readyz", nil, nil)
+		assert.Equal(t, 503, status)
+		assert.Equal(t, HealthStatusDown, body["status"])
+		check := ConvertToMap(ConvertToMap(body["checks"])["dependency"])
+		assert.Equal(t, "unavailable", check["error"])
+
+		status, _ = RunTestRequestRaw(http, "GET", "/live", nil, nil)
+		assert.Equal(t, 200, status)
+	})
+}
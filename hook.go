@@ -0,0 +1,112 @@
+package fluxgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/fx"
+)
+
+// HookFunc runs while the application starts or stops.
+type HookFunc func(ctx context.Context) error
+
+type moduleHook struct {
+	module      string
+	stop        bool
+	order       int
+	constructor interface{}
+	fn          HookFunc
+}
+
+// HookOption configures a module lifecycle hook.
+type HookOption func(h *moduleHook)
+
+// HookOrder sets the position of the hook among all module hooks. Default: 0.
+// Lower orders start first and stop last; equal orders keep registration order.
+func HookOrder(order int) HookOption {
+	return func(h *moduleHook) {
+		h.order = order
+	}
+}
+
+// OnStart runs fn once resources (Database, Redis, ...) are up and before HTTP, gRPC, Kafka and cron start.
+// fn is either a HookFunc or a function resolved by fx that returns a HookFunc,
+// e.g. func(db *fluxgo.Database) fluxgo.HookFunc.
+func (f *FluxModule) OnStart(fn interface{}, opts ...HookOption) *FluxModule {
+	return f.addHook(false, fn, opts)
+}
+
+// OnStop runs fn after HTTP, gRPC, Kafka and cron stopped and before resources close.
+// fn accepts the same forms as OnStart.
+func (f *FluxModule) OnStop(fn interface{}, opts ...HookOption) *FluxModule {
+	return f.addHook(true, fn, opts)
+}
+
+func (f *FluxModule) addHook(stop bool, fn interface{}, opts []HookOption) *FluxModule {
+	constructor := fn
+	switch hook := fn.(type) {
+	case HookFunc:
+		constructor = func() HookFunc { return hook }
+	case func(ctx context.Context) error:
+		constructor = func() HookFunc { return hook }
+	}
+
+	h := &moduleHook{module: f.Name, stop: stop, constructor: constructor}
+	for _, opt := range opts {
+		opt(h)
+	}
+	f.hooks = append(f.hooks, h)
+
+	return f
+}
+
+// resolveResult provides constructor under the name tag and hands its result to capture.
+// private keeps the provided value inside the enclosing fx.Module.
+func resolveResult[T any](tag string, constructor interface{}, capture func(T), private bool) fx.Option {
+	provide := []interface{}{fx.Annotate(constructor, fx.ResultTags(tag))}
+	if private {
+		provide = append(provide, fx.Private)
+	}
+
+	return fx.Options(
+		fx.Provide(provide...),
+		fx.Invoke(fx.Annotate(capture, fx.ParamTags(tag))),
+	)
+}
+
+// moduleHooksOption appends the hooks of every module to the lifecycle, between resources and ingress.
+// Module invokes run before the root ones, so every hook is resolved by the time it executes.
+func (f *FluxGo) moduleHooksOption() fx.Option {
+	return fx.Invoke(func(lc fx.Lifecycle) {
+		hooks := []*moduleHook{}
+		for _, module := range f.modules {
+			hooks = append(hooks, module.hooks...)
+		}
+		sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].order < hooks[j].order })
+
+		for _, hook := range hooks {
+			h := hook
+			if h.fn == nil {
+				continue
+			}
+
+			if h.stop {
+				lc.Append(fx.Hook{OnStop: func(ctx context.Context) error {
+					if err := h.fn(ctx); err != nil {
+						return fmt.Errorf("module %s stop hook: %w", h.module, err)
+					}
+					return nil
+				}})
+				continue
+			}
+
+			lc.Append(fx.Hook{OnStart: func(ctx context.Context) error {
+				if err := h.fn(ctx); err != nil {
+					return fmt.Errorf("module %s start hook: %w", h.module, err)
+				}
+				return nil
+			}})
+		}
+	})
+}
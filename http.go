@@ -6,9 +6,10 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
@@ -27,13 +28,29 @@ type HttpConfigData struct {
 type HttpParams struct {
 	fx.In
 
+	Health     *Health
 	Apm        *Apm        `optional:"true"`
 	Prometheus *Prometheus `optional:"true"`
 }
 
 func (f *FluxGo) AddHttp(opt HttpOptions, configApp HttpConfig) *FluxGo {
+	f.markProvided(DependencyHttp)
+
+	if opt.Inventory != nil && opt.Inventory.Permission == nil {
+		f.dependencies = append(f.dependencies, fx.Error(fmt.Errorf("InventoryOptions.Permission is required to expose the inventory")))
+		return f
+	}
+
 	f.AddDependency(func(params HttpParams) *Http {
 		opt.FiberConfig.DisableStartupMessage = true
+
+		var http *Http
+		if opt.ErrorFormatter != nil && opt.FiberConfig.ErrorHandler == nil {
+			opt.FiberConfig.ErrorHandler = func(c *fiber.Ctx, err error) error {
+				return http.fiberErrorHandler(f, c, err)
+			}
+		}
+
 		app := fiber.New(opt.FiberConfig)
 
 		if params.Apm != nil {
@@ -58,21 +75,25 @@ func (f *FluxGo) AddHttp(opt HttpOptions, configApp HttpConfig) *FluxGo {
 			app.Get("/live", func(c *fiber.Ctx) error {
 				return c.SendStatus(fiber.StatusOK)
 			})
-			app.Get("/health", func(c *fiber.Ctx) error {
-				return c.SendStatus(fiber.StatusOK)
-			})
-			app.Get("/readyz", func(c *fiber.Ctx) error {
-				return c.SendStatus(fiber.StatusOK)
-			})
+			app.Get("/health", params.Health.fiberHandler())
+			app.Get("/readyz", params.Health.fiberHandler())
 		}
 
-		http := &Http{app: app, port: opt.Port, routers: make(map[string]*fiber.Router), permissions: opt.Permissions}
+		http = &Http{app: app, port: opt.Port, routers: make(map[string]*fiber.Router), errorFormatter: opt.ErrorFormatter, routeTimeout: opt.RouteTimeout, versioning: opt.Versioning}
+		if opt.Permissions != nil {
+			http.SetPermissions(*opt.Permissions)
+		}
+		f.sources.http = http
 
 		if params.Prometheus != nil {
 			http.app.Use(params.Prometheus.Middleware(app, "/metrics"))
 		}
 
-		http.GetValidator()
+		http.validator = f.Validator()
+
+		if opt.Inventory != nil {
+			f.registerInventoryRoute(http, *opt.Inventory)
+		}
 
 		configApp(HttpConfigData{
 			Http:       http,
@@ -80,12 +101,15 @@ func (f *FluxGo) AddHttp(opt HttpOptions, configApp HttpConfig) *FluxGo {
 			Prometheus: params.Prometheus,
 		})
 
+		http.specInfo = openAPIInfo{title: f.Name, version: f.Version}
+
 		if opt.Swagger != nil {
 			swOpts := *opt.Swagger
-			title := swOpts.Title
-			if title == "" {
-				title = f.Name
+			if swOpts.Title != "" {
+				http.specInfo.title = swOpts.Title
 			}
+			http.specInfo.description = swOpts.Description
+
 			for prefix := range http.routers {
 				p := prefix
 				path := swOpts.Path
@@ -98,14 +122,33 @@ func (f *FluxGo) AddHttp(opt HttpOptions, configApp HttpConfig) *FluxGo {
 					return c.SendString(swaggerUIHTML(specPath))
 				})
 				http.app.Get(specPath, func(c *fiber.Ctx) error {
-					return c.JSON(http.buildOpenAPISpec(title, f.Version, swOpts.Description, p))
+					return c.JSON(http.OpenAPISpec(p))
 				})
+
+				if opt.Versioning == nil {
+					continue
+				}
+				for _, version := range opt.Versioning.Versions {
+					name := version.Name
+					versionSpecPath := p + path + "/v" + name + "/openapi.json"
+					http.app.Get(p+path+"/v"+name, func(c *fiber.Ctx) error {
+						c.Set("Content-Type", "text/html; charset=utf-8")
+						return c.SendString(swaggerUIHTML(versionSpecPath))
+					})
+					http.app.Get(versionSpecPath, func(c *fiber.Ctx) error {
+						return c.JSON(http.OpenAPIVersionSpec(p, name))
+					})
+				}
 			}
 		}
 
 		return http
 	})
 	f.AddInvoke(func(lc fx.Lifecycle, http *Http) error {
+		if !f.isServing(RoleHttp) {
+			return nil
+		}
+
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				if err := http.start(ctx); err != nil {
@@ -131,10 +174,6 @@ func (f *FluxGo) AddHttp(opt HttpOptions, configApp HttpConfig) *FluxGo {
 	return f
 }
 
-type Validator struct {
-	*validator.Validate
-}
-
 // SwaggerRouterHeader defines a header parameter shown in Swagger for all routes in a router group.
 type SwaggerRouterHeader struct {
 	Name        string
@@ -173,10 +212,25 @@ type Http struct {
 	app           *fiber.App
 	routers       map[string]*fiber.Router
 	validator     *Validator
-	permissions   *Permissions
+	permissions   atomic.Pointer[Permissions]
 	docs          []routeDoc
 	moduleTags    map[string]SwaggerModuleTag
 	routerSwagger map[string]SwaggerRouterConfig
+	specInfo      openAPIInfo
+
+	errorFormatter ErrorFormatter
+	rateLimits     *MemoryRateLimitStore
+	routeTimeout   time.Duration
+
+	permissionLoader *permissionLoader
+
+	versioning      *VersioningOptions
+	versionedRoutes map[string]*versionedRoute
+
+	streamsMu sync.Mutex
+	streams   map[*context.CancelFunc]struct{}
+
+	wsHub wsHub
 }
 
 func (h *Http) registerModuleTag(name string, tag SwaggerModuleTag) {
@@ -193,47 +247,19 @@ func (h *Http) registerRouterSwagger(prefix string, config SwaggerRouterConfig)
 	h.routerSwagger[prefix] = config
 }
 
-func (h *Http) GetPermissions(ctx context.Context) []PermissionRule {
-	if role := ctx.Value(RoleContextKey); role != nil && h.permissions != nil {
-		return h.permissions.Get(role.(string))
+// memoryRateLimitStore is shared by the rate limited routes when Redis is not added.
+func (h *Http) memoryRateLimitStore() *MemoryRateLimitStore {
+	if h.rateLimits == nil {
+		h.rateLimits = NewMemoryRateLimitStore()
 	}
 
-	return nil
+	return h.rateLimits
 }
 
 type contextKey string
 
 const RoleContextKey contextKey = "role"
 
-type PermissionRule struct {
-	Action  string `json:"action"`
-	Subject string `json:"subject"`
-}
-type Permissions map[string][]PermissionRule
-
-func (perm Permissions) Get(role string) []PermissionRule {
-	rules, exists := perm[role]
-	if !exists {
-		return []PermissionRule{}
-	}
-
-	return rules
-}
-func (perm Permissions) Can(role, action, subject string) bool {
-	rules, exists := perm[role]
-	if !exists {
-		return false
-	}
-
-	for _, rule := range rules {
-		if (rule.Action == action || rule.Action == "manage") && (rule.Subject == subject || rule.Subject == "all") {
-			return true
-		}
-	}
-
-	return false
-}
-
 type HttpOptions struct {
 	Port            int
 	ConfigApp       func(*fiber.App)
@@ -241,6 +267,14 @@ type HttpOptions struct {
 	AddHealthRoutes bool
 	Permissions     *Permissions
 	Swagger         *SwaggerOptions
+	Inventory       *InventoryOptions
+	// ErrorFormatter renders every GlobalError; use ProblemDetailsFormatter for problem+json.
+	// Default: DefaultErrorFormatter
+	ErrorFormatter ErrorFormatter
+	// RouteTimeout is the deadline of every route without RouteIncome.Timeout. Default: none
+	RouteTimeout time.Duration
+	// Versioning dispatches the routes declaring RouteIncome.Version by the requested version.
+	Versioning *VersioningOptions
 
 	Cors        *cors.Config
 	FiberConfig fiber.Config
@@ -285,8 +319,17 @@ func (h *Http) start(ctx context.Context) error {
 		return ctx.Err()
 	}
 }
+
+// stop stops accepting connections and waits for in-flight requests until ctx expires.
+// When ctx carries no deadline a 10 second limit is applied.
 func (h *Http) stop(ctx context.Context) error {
-	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	h.closeStreams()
+	h.wsHub.close()
+
+	shutdownCtx, cancel := ctx, context.CancelFunc(func() {})
+	if _, ok := ctx.Deadline(); !ok {
+		shutdownCtx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	}
 	defer cancel()
 
 	done := make(chan error, 1)
@@ -324,49 +367,23 @@ func (h *Http) GetRouter(prefix string) *fiber.Router {
 	return route
 }
 
-func (h *Http) GetValidator() *Validator {
-	if h.validator != nil {
-		return h.validator
+// Validator returns the validator shared by HTTP routes and use case bindings.
+func (f *FluxGo) Validator() *Validator {
+	if f.validator == nil {
+		f.validator = newValidator()
 	}
 
-	var validate = validator.New()
-	h.validator = &Validator{validate}
-
-	return h.validator
+	return f.validator
 }
-func (v *Validator) Run(data interface{}) (bool, *GlobalError) {
-	validate := v.Validate
-
-	validationErrors := []errorResponse{}
 
-	if errs := validate.Struct(data); errs != nil {
-		for _, err := range errs.(validator.ValidationErrors) {
-			var elem errorResponse
-
-			elem.FailedField = err.Field()
-			elem.Tag = err.Tag()
-			elem.Value = err.Value()
-
-			validationErrors = append(validationErrors, elem)
-		}
-	}
-
-	hasError := len(validationErrors) > 0
-
-	errors := &GlobalError{
-		Code:    "error.validation",
-		Success: false,
-		Errors:  validationErrors,
-		Status:  400,
+func (h *Http) GetValidator() *Validator {
+	if h.validator != nil {
+		return h.validator
 	}
 
-	return hasError, errors
-}
+	h.validator = newValidator()
 
-type errorResponse struct {
-	FailedField string      `json:"failed_field"`
-	Tag         string      `json:"tag"`
-	Value       interface{} `json:"value"`
+	return h.validator
 }
 
 type GlobalResponse struct {
@@ -390,6 +407,14 @@ func ErrorInternalError(message string) *GlobalError {
 		Success: false,
 	}
 }
+func ErrorGatewayTimeout(message string) *GlobalError {
+	return &GlobalError{
+		Message: message,
+		Code:    "timeout",
+		Status:  http.StatusGatewayTimeout,
+		Success: false,
+	}
+}
 func ErrorNotFound(message string) *GlobalError {
 	return &GlobalError{
 		Message: message,
@@ -0,0 +1,287 @@
+package fluxgo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL = 24 * time.Hour
+)
+
+// idempotencyLockTTL bounds how long a request is reported in flight, so a crashed
+// instance does not block its key until IdempotencyTTL. It is renewed while the handler runs.
+var idempotencyLockTTL = time.Minute
+
+// idempotencySkipHeaders are set on every response and are not replayed.
+var idempotencySkipHeaders = map[string]bool{
+	fiber.HeaderDate:          true,
+	fiber.HeaderContentLength: true,
+	fiber.HeaderServer:        true,
+	fiber.HeaderConnection:    true,
+	fiber.HeaderRetryAfter:    true,
+	"Ratelimit-Limit":         true,
+	"Ratelimit-Remaining":     true,
+	"Ratelimit-Reset":         true,
+}
+
+// idempotencyRecord is the cached state of an Idempotency-Key; Pending until the first response is stored.
+type idempotencyRecord struct {
+	Pending     bool              `json:"pending"`
+	Fingerprint string            `json:"fingerprint"`
+	Status      int               `json:"status,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+func (i *RouteIncome) idempotencyTTL() time.Duration {
+	if i.IdempotencyTTL > 0 {
+		return i.IdempotencyTTL
+	}
+
+	return defaultIdempotencyTTL
+}
+
+// idempotencyKey scopes key to the route and the caller, hashed so route patterns never
+// reach the cache key.
+func (i *RouteIncome) idempotencyKey(c *fiber.Ctx, serviceName, route, key string) string {
+	sum := sha256.Sum256([]byte(route + "\n" + idempotencyCaller(c) + "\n" + key))
+
+	return i.cacheVal(serviceName, "idempotency:"+hex.EncodeToString(sum[:]))
+}
+
+// idempotencyCaller identifies who sent the request, so callers never replay each other's responses.
+func idempotencyCaller(c *fiber.Ctx) string {
+	ctx := c.UserContext()
+	authorization := sha256.Sum256([]byte(c.Get(fiber.HeaderAuthorization)))
+
+	return fmt.Sprintf("%v|%v|%v|%s", ctx.Value(RoleContextKey), ctx.Value(SubjectContextKey), ctx.Value(TenantContextKey), hex.EncodeToString(authorization[:]))
+}
+
+// idempotent runs serve once per Idempotency-Key and caller: repeats with the same URL and
+// body replay the stored response, repeats while the first one runs get 409 and a different
+// URL or body gets 422. Requests without the header, or failing with 5xx, are not stored.
+// A handler still running after its 504 keeps the key in flight and stores its response
+// once it finishes.
+func (i *RouteIncome) idempotent(c *fiber.Ctx, f *FluxGo, apm *Apm, http *Http, route string, serve func(c *fiber.Ctx) error) error {
+	header := c.Get(IdempotencyKeyHeader)
+	if header == "" || i.Cache == nil {
+		return serve(c)
+	}
+
+	// The record outlives the request, so it is stored even if the request timed out.
+	ctx, span := apm.StartSpan(context.WithoutCancel(c.UserContext()), "cache/idempotency")
+	defer span.End()
+
+	key := i.idempotencyKey(c, f.GetCleanName(), route, header)
+	sum := sha256.Sum256(append([]byte(c.OriginalURL()+"\n"), c.Body()...))
+	fingerprint := hex.EncodeToString(sum[:])
+
+	if record := i.idempotencyRecord(ctx, key); record != nil {
+		return i.replay(c, http, record, fingerprint)
+	}
+
+	reserved, err := i.reserve(ctx, key, idempotencyRecord{Pending: true, Fingerprint: fingerprint})
+	if err != nil {
+		span.SetError(err)
+		f.LogError("HTTP", fmt.Sprintf("Idempotency store failed: %v", err))
+		return serve(c)
+	}
+	if !reserved {
+		if record := i.idempotencyRecord(ctx, key); record != nil {
+			return i.replay(c, http, record, fingerprint)
+		}
+		return http.SendError(c, errorIdempotencyInFlight())
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			if err := i.release(ctx, key); err != nil {
+				span.SetError(err)
+			}
+		}
+	}()
+
+	stopRenew := i.renew(ctx, key, idempotencyRecord{Pending: true, Fingerprint: fingerprint})
+	err = serve(c)
+
+	// The handler outlived the deadline: keep the key in flight until it finishes.
+	var timedOut *routeTimedOut
+	if errors.As(err, &timedOut) {
+		completed = true
+		go i.storeDetached(ctx, key, fingerprint, timedOut.result, stopRenew)
+		return err
+	}
+
+	stopRenew()
+	if err != nil {
+		return err
+	}
+
+	status := c.Response().StatusCode()
+	if status >= fiber.StatusInternalServerError {
+		return nil
+	}
+
+	record := idempotencyRecord{
+		Fingerprint: fingerprint,
+		Status:      status,
+		Headers:     map[string]string{},
+		Body:        append([]byte{}, c.Response().Body()...),
+	}
+	c.Response().Header.VisitAll(func(name, value []byte) {
+		if !idempotencySkipHeaders[string(name)] {
+			record.Headers[string(name)] = string(value)
+		}
+	})
+
+	if err := i.Cache.Store(ctx, key, record, i.idempotencyTTL()); err != nil {
+		span.SetError(err)
+		return nil
+	}
+	completed = true
+
+	return nil
+}
+
+// storeDetached stores the response of a handler that finished after its 504 was sent, so
+// retries replay it instead of running it again. Failed handlers release the key.
+func (i *RouteIncome) storeDetached(ctx context.Context, key, fingerprint string, result <-chan handlerResult, stopRenew func()) {
+	r := <-result
+	stopRenew()
+
+	if record, ok := detachedRecord(r, fingerprint); ok && i.Cache.Store(ctx, key, record, i.idempotencyTTL()) == nil {
+		return
+	}
+
+	_ = i.release(ctx, key)
+}
+
+// detachedRecord renders r like serve does; ok is false for results that are not stored.
+func detachedRecord(r handlerResult, fingerprint string) (idempotencyRecord, bool) {
+	record := idempotencyRecord{Fingerprint: fingerprint, Status: fiber.StatusOK, Headers: map[string]string{}}
+	if r.recovered != nil || r.gErr != nil {
+		return record, false
+	}
+	if r.res == nil {
+		return record, true
+	}
+
+	record.Status = r.res.Status
+	if r.res.Content != nil {
+		body, err := json.Marshal(r.res.Content)
+		if err != nil {
+			return record, false
+		}
+		record.Body = body
+		record.Headers[fiber.HeaderContentType] = fiber.MIMEApplicationJSON
+	}
+
+	return record, record.Status < fiber.StatusInternalServerError
+}
+
+func (i *RouteIncome) idempotencyRecord(ctx context.Context, key string) *idempotencyRecord {
+	cached := i.Cache.Get(ctx, key)
+	if cached == nil {
+		return nil
+	}
+
+	record := &idempotencyRecord{}
+	if err := json.Unmarshal([]byte(*cached), record); err != nil {
+		return nil
+	}
+
+	return record
+}
+
+// reserve stores the pending record unless the key exists, atomically when the cache is an ICacheReserver.
+func (i *RouteIncome) reserve(ctx context.Context, key string, record idempotencyRecord) (bool, error) {
+	if reserver, ok := i.Cache.(ICacheReserver); ok {
+		return reserver.StoreIfAbsent(ctx, key, record, idempotencyLockTTL)
+	}
+
+	if i.Cache.Get(ctx, key) != nil {
+		return false, nil
+	}
+
+	return true, i.Cache.Store(ctx, key, record, idempotencyLockTTL)
+}
+
+// renew keeps the pending record alive while the handler runs, so a handler slower than
+// idempotencyLockTTL is not run twice. The returned func stops it before the response is stored.
+func (i *RouteIncome) renew(ctx context.Context, key string, record idempotencyRecord) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(idempotencyLockTTL / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = i.Cache.Store(ctx, key, record, idempotencyLockTTL)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// release removes the pending record of a request that was not stored.
+func (i *RouteIncome) release(ctx context.Context, key string) error {
+	if reserver, ok := i.Cache.(ICacheReserver); ok {
+		return reserver.Delete(ctx, key)
+	}
+
+	// The key is a service prefix and a hex digest, with no pattern characters.
+	return i.Cache.Invalidate(ctx, []string{key})
+}
+
+func (i *RouteIncome) replay(c *fiber.Ctx, http *Http, record *idempotencyRecord, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		return http.SendError(c, &GlobalError{
+			Message: "Idempotency-Key was already used with a different request",
+			Code:    "error.idempotency_key_reused",
+			Status:  fiber.StatusUnprocessableEntity,
+			Success: false,
+		})
+	}
+	if record.Pending {
+		return http.SendError(c, errorIdempotencyInFlight())
+	}
+
+	for name, value := range record.Headers {
+		c.Set(name, value)
+	}
+	c.Set(IdempotencyReplayedHeader, "true")
+
+	return c.Status(record.Status).Send(record.Body)
+}
+
+func errorIdempotencyInFlight() *GlobalError {
+	return &GlobalError{
+		Message: "A request with the same Idempotency-Key is in progress",
+		Code:    "error.idempotency_in_flight",
+		Status:  fiber.StatusConflict,
+		Success: false,
+	}
+}
@@ -0,0 +1,263 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryTestCache struct {
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func (c *memoryTestCache) Get(ctx context.Context, key string) *string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if expires, ok := c.expires[key]; ok && time.Now().After(expires) {
+		delete(c.values, key)
+	}
+	if value, exists := c.values[key]; exists {
+		return &value
+	}
+	return nil
+}
+
+func (c *memoryTestCache) Store(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = string(content)
+	if c.expires == nil {
+		c.expires = map[string]time.Time{}
+	}
+	c.expires[key] = time.Now().Add(ttl)
+	return nil
+}
+
+func (c *memoryTestCache) StoreIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if c.Get(ctx, key) != nil {
+		return false, nil
+	}
+	return true, c.Store(ctx, key, value, ttl)
+}
+
+func (c *memoryTestCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func (c *memoryTestCache) Invalidate(ctx context.Context, keys []string) error {
+	return c.Delete(ctx, keys...)
+}
+
+type idempotencyTestHandler struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *idempotencyTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	order := income.(*idempotencyTestOrder)
+	if order.Item == "slow" {
+		close(h.started)
+		<-h.release
+	}
+	if order.Item == "sleepy" {
+		time.Sleep(150 * time.Millisecond)
+	}
+	if order.Item == "broken" {
+		h.calls.Add(1)
+		return nil, ErrorInternalError("failed")
+	}
+
+	c.Set("Location", "/api/orders/"+order.Item)
+	return &GlobalResponse{Status: 201, Content: fiber.Map{"call": h.calls.Add(1)}}, nil
+}
+
+type idempotencyTestSlowHandler struct {
+	calls atomic.Int32
+}
+
+func (h *idempotencyTestSlowHandler) Handle(ctx context.Context, req *idempotencyTestOrder) (*idempotencyTestOrder, *GlobalError) {
+	h.calls.Add(1)
+	time.Sleep(200 * time.Millisecond)
+	return req, nil
+}
+
+type idempotencyTestOrder struct {
+	Item string `json:"item"`
+}
+
+func TestIdempotency(t *testing.T) {
+	handler := &idempotencyTestHandler{started: make(chan struct{}), release: make(chan struct{})}
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *idempotencyTestHandler { return handler }).
+		Route(POST[idempotencyTestHandler]("/api", "/orders", RouteIncome{
+			Entity:     idempotencyTestOrder{},
+			FromBody:   true,
+			Idempotent: true,
+			Cache:      &memoryTestCache{values: map[string]string{}},
+		})))
+
+	_, http := flux.GetTestApp(t)
+
+	requestAs := func(authorization, key, item string) (int, string, fiber.Map) {
+		req := httptest.NewRequest("POST", "/api/orders", strings.NewReader(`{"item":"`+item+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		res, err := http.GetApp().Test(req, 5000)
+		assert.NoError(t, err)
+
+		body := fiber.Map{}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return res.StatusCode, res.Header.Get("Location") + "|" + res.Header.Get(IdempotencyReplayedHeader), body
+	}
+	request := func(key, item string) (int, string, fiber.Map) { return requestAs("", key, item) }
+
+	t.Run("Should replay the first response for a repeated key", func(t *testing.T) {
+		status, headers, first := request("key-1", "book")
+		assert.Equal(t, 201, status)
+		assert.Equal(t, "/api/orders/book|", headers)
+
+		status, headers, second := request("key-1", "book")
+		assert.Equal(t, 201, status)
+		assert.Equal(t, "/api/orders/book|true", headers)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("Should reject a key reused with a different body", func(t *testing.T) {
+		status, _, body := request("key-1", "pen")
+		assert.Equal(t, 422, status)
+		assert.Equal(t, "error.idempotency_key_reused", body["code"])
+	})
+
+	t.Run("Should reply 409 while the original request is in flight", func(t *testing.T) {
+		done := make(chan int)
+		go func() {
+			status, _, _ := request("key-2", "slow")
+			done <- status
+		}()
+
+		<-handler.started
+		status, _, body := request("key-2", "slow")
+		assert.Equal(t, 409, status)
+		assert.Equal(t, "error.idempotency_in_flight", body["code"])
+
+		close(handler.release)
+		assert.Equal(t, 201, <-done)
+	})
+
+	t.Run("Should not store 5xx responses", func(t *testing.T) {
+		before := handler.calls.Load()
+
+		status, _, _ := request("key-3", "broken")
+		assert.Equal(t, 500, status)
+		status, _, _ = request("key-3", "broken")
+		assert.Equal(t, 500, status)
+
+		assert.Equal(t, before+2, handler.calls.Load())
+	})
+
+	t.Run("Should not replay responses to other callers", func(t *testing.T) {
+		status, headers, first := requestAs("Bearer alice", "key-4", "book")
+		assert.Equal(t, 201, status)
+		assert.Equal(t, "/api/orders/book|", headers)
+
+		status, headers, second := requestAs("Bearer bob", "key-4", "book")
+		assert.Equal(t, 201, status)
+		assert.Equal(t, "/api/orders/book|", headers)
+		assert.NotEqual(t, first["call"], second["call"])
+
+		_, headers, _ = requestAs("Bearer alice", "key-4", "book")
+		assert.Equal(t, "/api/orders/book|true", headers)
+	})
+
+	t.Run("Should keep the key in flight while a slow handler runs", func(t *testing.T) {
+		lockTTL := idempotencyLockTTL
+		idempotencyLockTTL = 40 * time.Millisecond
+		defer func() { idempotencyLockTTL = lockTTL }()
+
+		done := make(chan int)
+		go func() {
+			status, _, _ := request("key-5", "sleepy")
+			done <- status
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		status, _, _ := request("key-5", "sleepy")
+		assert.Equal(t, 409, status)
+		assert.Equal(t, 201, <-done)
+	})
+
+	t.Run("Should run every request without the header", func(t *testing.T) {
+		_, _, first := request("", "book")
+		_, _, second := request("", "book")
+		assert.NotEqual(t, first["call"], second["call"])
+	})
+}
+
+func TestIdempotencyTimeout(t *testing.T) {
+	handler := &idempotencyTestSlowHandler{}
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *idempotencyTestSlowHandler { return handler }).
+		Route(TypedPOST[idempotencyTestSlowHandler, idempotencyTestOrder, idempotencyTestOrder]("/api", "/orders", RouteIncome{
+			FromBody:   true,
+			Idempotent: true,
+			Timeout:    20 * time.Millisecond,
+			Cache:      &memoryTestCache{values: map[string]string{}},
+		})))
+
+	_, http := flux.GetTestApp(t)
+
+	request := func() (int, ParsedResponse) {
+		return RunTestRequest(http, "POST", "/api/orders", map[string]string{"item": "book"}, &Headers{IdempotencyKeyHeader: "key-1"})
+	}
+
+	t.Run("Should keep the key in flight until a timed out handler finishes", func(t *testing.T) {
+		status, _ := request()
+		assert.Equal(t, 504, status)
+
+		status, body := request()
+		assert.Equal(t, 409, status)
+		assert.Equal(t, "error.idempotency_in_flight", body["code"])
+
+		assert.Eventually(t, func() bool {
+			status, body := request()
+			return status == 200 && body["item"] == "book"
+		}, time.Second, 20*time.Millisecond)
+		assert.Equal(t, int32(1), handler.calls.Load())
+	})
+}
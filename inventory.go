@@ -0,0 +1,142 @@
+package fluxgo
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultInventoryPath = "/_fluxgo/inventory"
+
+// InventoryOptions exposes FluxGo.Inventory over HTTP.
+type InventoryOptions struct {
+	// Path of the endpoint. Default: "/_fluxgo/inventory"
+	Path string
+	// Permission required to read the inventory, checked like RouteIncome.Permission.
+	// Required, since the inventory lists every route, permission and module.
+	Permission *RoutePermission
+	// Middleware runs before the endpoint, e.g. to authenticate the caller.
+	Middleware []fiber.Handler
+}
+
+// Inventory lists everything the application exposes.
+type Inventory struct {
+	Routes []InventoryRoute       `json:"routes"`
+	Topics []InventoryTopic       `json:"topics"`
+	Crons  []InventoryCron        `json:"crons"`
+	Grpc   []InventoryGrpcService `json:"grpc"`
+	Tools  []InventoryTool        `json:"tools"`
+}
+type InventoryRoute struct {
+	Method     string           `json:"method"`
+	Path       string           `json:"path"`
+	Module     string           `json:"module"`
+	Permission *RoutePermission `json:"permission,omitempty"`
+	CacheTTL   string           `json:"cache_ttl,omitempty"`
+}
+type InventoryTopic struct {
+	Topic   string `json:"topic"`
+	Module  string `json:"module,omitempty"`
+	Handler string `json:"handler,omitempty"`
+}
+type InventoryCron struct {
+	Name    string     `json:"name"`
+	Spec    string     `json:"spec"`
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+type InventoryGrpcService struct {
+	Service string   `json:"service"`
+	Methods []string `json:"methods"`
+}
+type InventoryTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// inventorySources keeps the subsystem instances created by fx so Inventory can read them.
+type inventorySources struct {
+	http  *Http
+	kafka *Kafka
+	cron  *Cron
+	grpc  *Grpc
+	tools *Tools
+}
+
+// Inventory returns the routes, topics, cron jobs, gRPC services and tools registered so far.
+// It is complete once the application has been built (after fx invokes ran).
+func (f *FluxGo) Inventory() Inventory {
+	inv := Inventory{
+		Routes: []InventoryRoute{},
+		Topics: []InventoryTopic{},
+		Crons:  []InventoryCron{},
+		Grpc:   []InventoryGrpcService{},
+		Tools:  []InventoryTool{},
+	}
+
+	if h := f.sources.http; h != nil {
+		for _, doc := range h.docs {
+			route := InventoryRoute{Method: doc.method, Path: doc.path, Module: doc.module, Permission: doc.permission}
+			if doc.cacheTTL > 0 {
+				route.CacheTTL = doc.cacheTTL.String()
+			}
+			inv.Routes = append(inv.Routes, route)
+		}
+		sort.SliceStable(inv.Routes, func(i, j int) bool { return inv.Routes[i].Path < inv.Routes[j].Path })
+	}
+
+	if k := f.sources.kafka; k != nil {
+		for _, consumer := range k.consumers {
+			inv.Topics = append(inv.Topics, InventoryTopic{Topic: consumer.topic, Module: consumer.module, Handler: consumer.handlerType})
+		}
+	}
+
+	if c := f.sources.cron; c != nil {
+		for _, job := range c.jobs {
+			entry := InventoryCron{Name: job.Name(), Spec: c.specs[job.Name()]}
+			if next, err := job.NextRun(); err == nil && !next.IsZero() {
+				entry.NextRun = &next
+			}
+			inv.Crons = append(inv.Crons, entry)
+		}
+	}
+
+	if g := f.sources.grpc; g != nil {
+		for name, info := range g.server.GetServiceInfo() {
+			methods := make([]string, 0, len(info.Methods))
+			for _, method := range info.Methods {
+				methods = append(methods, method.Name)
+			}
+			sort.Strings(methods)
+			inv.Grpc = append(inv.Grpc, InventoryGrpcService{Service: name, Methods: methods})
+		}
+		sort.Slice(inv.Grpc, func(i, j int) bool { return inv.Grpc[i].Service < inv.Grpc[j].Service })
+	}
+
+	if t := f.sources.tools; t != nil {
+		for _, tool := range t.tools {
+			inv.Tools = append(inv.Tools, InventoryTool{Name: tool.Name(), Description: tool.Description()})
+		}
+		sort.Slice(inv.Tools, func(i, j int) bool { return inv.Tools[i].Name < inv.Tools[j].Name })
+	}
+
+	return inv
+}
+
+func (f *FluxGo) registerInventoryRoute(http *Http, opt InventoryOptions) {
+	path := opt.Path
+	if path == "" {
+		path = defaultInventoryPath
+	}
+
+	handlers := append([]fiber.Handler{}, opt.Middleware...)
+	handlers = append(handlers, func(c *fiber.Ctx) error {
+		if err := http.checkPermission(c.UserContext(), opt.Permission, nil); err != nil {
+			return http.SendError(c, err)
+		}
+
+		return c.JSON(f.Inventory())
+	})
+
+	http.app.Get(path, handlers...)
+}
@@ -0,0 +1,73 @@
+package fluxgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type inventoryTestCache struct{}
+
+func (inventoryTestCache) Get(ctx context.Context, key string) *string { return nil }
+func (inventoryTestCache) Store(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+func (inventoryTestCache) Invalidate(ctx context.Context, keys []string) error { return nil }
+
+func TestInventory(t *testing.T) {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddCron()
+	flux.AddHttp(HttpOptions{
+		Inventory: &InventoryOptions{Permission: &RoutePermission{Action: "read", Subject: "inventory"}},
+	}, func(HttpConfigData) {})
+	flux.AddModule(Module("test").
+		AddHandler(
+			func() *rolesTestHandler { return &rolesTestHandler{} },
+			func() *commandTestCron { return &commandTestCron{} },
+		).
+		Route(
+			GET[rolesTestHandler]("", "/ping", RouteIncome{
+				Permission: &RoutePermission{Action: "read", Subject: "ping"},
+				Cache:      inventoryTestCache{},
+				CacheTTL:   time.Minute,
+			}),
+			CronDef[commandTestCron]("*/5 * * * *"),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should list routes with module, permission and cache", func(t *testing.T) {
+		inv := flux.Inventory()
+
+		assert.Len(t, inv.Routes, 1)
+		assert.Equal(t, "GET", inv.Routes[0].Method)
+		assert.Equal(t, "/ping", inv.Routes[0].Path)
+		assert.Equal(t, "test", inv.Routes[0].Module)
+		assert.Equal(t, "ping", inv.Routes[0].Permission.Subject)
+		assert.Equal(t, "1m0s", inv.Routes[0].CacheTTL)
+	})
+
+	t.Run("Should list cron jobs with their spec", func(t *testing.T) {
+		inv := flux.Inventory()
+
+		assert.Len(t, inv.Crons, 1)
+		assert.Equal(t, "test.commandTestCron", inv.Crons[0].Name)
+		assert.Equal(t, "*/5 * * * *", inv.Crons[0].Spec)
+	})
+
+	t.Run("Should protect the inventory endpoint", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "GET", "/_fluxgo/inventory", nil, nil)
+		assert.Equal(t, 401, status)
+	})
+
+	t.Run("Should require a permission to expose the inventory", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddHttp(HttpOptions{Inventory: &InventoryOptions{}}, func(HttpConfigData) {})
+
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "InventoryOptions.Permission is required")
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"net"
+	"sync/atomic"
 
 	"github.com/IBM/sarama"
 	"go.opentelemetry.io/otel"
@@ -23,7 +26,9 @@ type Kafka struct {
 
 	consumers []Consumer
 
-	consumerIsRunning bool
+	consumerIsRunning atomic.Bool
+	cancel            context.CancelFunc
+	done              chan struct{}
 	opts              KafkaOptions
 }
 
@@ -33,6 +38,9 @@ type KafkaConsumerGroup sarama.ConsumerGroup
 type Consumer struct {
 	topic   string
 	handler MessageHandler
+
+	module      string
+	handlerType string
 }
 type MessageHandler func(ctx context.Context, data []byte) error
 
@@ -59,6 +67,8 @@ type ConsumerInterface interface {
 }
 
 func (f *FluxGo) AddKafka(data KafkaOptions) *FluxGo {
+	f.markProvided(DependencyKafka)
+
 	f.AddDependency(func(apm *Apm) *Kafka {
 		kafka := Kafka{
 			apm:  apm,
@@ -75,7 +85,7 @@ func (f *FluxGo) AddKafka(data KafkaOptions) *FluxGo {
 			kafka.producer = producer
 		}
 
-		if data.Consumer != nil {
+		if data.Consumer != nil && f.isServing(RoleKafka) {
 			consumer, err := setupConsumer(data)
 			if err != nil {
 				log.Printf("Failed to create consumer: %s\n", err)
@@ -85,18 +95,28 @@ func (f *FluxGo) AddKafka(data KafkaOptions) *FluxGo {
 			kafka.consumerGroup = consumer
 		}
 
+		f.sources.kafka = &kafka
+
 		return &kafka
 	})
 
 	f.AddInvoke(func(lc fx.Lifecycle, kafka *Kafka) error {
+		f.AddHealthCheck("kafka", kafka.Ping)
+
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				f.Log("KAFKA", "Connected")
+				if !f.isServing(RoleKafka) {
+					return nil
+				}
 				return kafka.start()
 			},
 			OnStop: func(ctx context.Context) error {
+				if err := kafka.stop(ctx); err != nil {
+					return err
+				}
 				f.Log("KAFKA", "Disconnected")
-				return kafka.stop()
+				return nil
 			},
 		})
 		return nil
@@ -120,12 +140,16 @@ func (k *Kafka) start() error {
 		apm:       k.apm,
 		consumers: k.consumers,
 	}
-	k.consumerIsRunning = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	k.done = make(chan struct{})
+	k.consumerIsRunning.Store(true)
 
 	go func() {
-		for k.consumerIsRunning {
-			ctx := context.Background()
+		defer close(k.done)
 
+		for k.consumerIsRunning.Load() {
 			if err := k.consumerGroup.Consume(ctx, topics, han); err != nil {
 				if errors.Is(err, sarama.ErrClosedConsumerGroup) {
 					return
@@ -139,23 +163,65 @@ func (k *Kafka) start() error {
 
 	return nil
 }
-func (k *Kafka) stop() error {
-	if err := k.consumerGroup.Close(); err != nil {
-		return err
+
+// stop ends the consumer session, waiting for the message being processed to finish
+// before closing the consumer group and the producer.
+func (k *Kafka) stop(ctx context.Context) error {
+	var err error
+
+	if k.consumerIsRunning.Swap(false) {
+		k.cancel()
+
+		select {
+		case <-k.done:
+		case <-ctx.Done():
+			err = errors.Join(err, fmt.Errorf("kafka consumers did not drain: %w", ctx.Err()))
+		}
 	}
-	k.consumerIsRunning = false
 
-	if err := k.producer.Close(); err != nil {
-		return err
+	if k.consumerGroup != nil {
+		if e := k.consumerGroup.Close(); e != nil {
+			err = errors.Join(err, e)
+		}
+	}
+	if k.producer != nil {
+		if e := k.producer.Close(); e != nil {
+			err = errors.Join(err, e)
+		}
 	}
 
-	return nil
+	return err
+}
+
+// Ping succeeds when at least one configured broker accepts a TCP connection.
+func (k *Kafka) Ping(ctx context.Context) error {
+	var err error
+	dialer := net.Dialer{}
+
+	for _, broker := range k.opts.Brokers {
+		conn, e := dialer.DialContext(ctx, "tcp", broker)
+		if e != nil {
+			err = errors.Join(err, e)
+			continue
+		}
+
+		return conn.Close()
+	}
+
+	if err == nil {
+		return fmt.Errorf("no kafka brokers configured")
+	}
+
+	return err
 }
 func (k *Kafka) AddConsumer(topic string, handler MessageHandler) error {
-	k.consumers = append(k.consumers, Consumer{
+	return k.addConsumer(Consumer{
 		topic:   topic,
 		handler: handler,
 	})
+}
+func (k *Kafka) addConsumer(consumer Consumer) error {
+	k.consumers = append(k.consumers, consumer)
 	return nil
 }
 func (k *Kafka) ProduceMessageJson(ctx context.Context, topic string, data interface{}, key *string) error {
@@ -250,11 +316,21 @@ func (ConsumerGroup) Cleanup(_ sarama.ConsumerGroupSession) error {
 	log.Printf("[KAFKA] Re-balancing will happen soon, current session will end")
 	return nil
 }
+
+// ConsumeClaim processes messages until the claim closes or the session is cancelled.
+// A message already being handled is always completed before returning.
 func (h ConsumerGroup) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for msg := range claim.Messages() {
-		h.handleMessage(msg, session)
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			h.handleMessage(msg, session)
+		case <-session.Context().Done():
+			return nil
+		}
 	}
-	return nil
 }
 func (h *ConsumerGroup) handleMessage(msg *sarama.ConsumerMessage, session sarama.ConsumerGroupSession) {
 	parentCtx := otel.GetTextMapPropagator().Extract(context.Background(), kafkaConsumerCarrier(msg.Headers))
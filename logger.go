@@ -16,7 +16,8 @@ import (
 
 type Logger struct {
 	*slog.Logger
-	opt LoggerOptions
+	opt   LoggerOptions
+	level *slog.LevelVar
 
 	provider *sdklog.LoggerProvider
 	file     *os.File
@@ -28,24 +29,38 @@ type LoggerInstance struct {
 
 type LoggerOptions struct {
 	// Options: console, file, otel
-	Type        string
+	Type string
+	// Options: debug, info, warn, error. Default: debug. Unknown levels fall back to info.
 	Level       string
 	LogFilePath string
 }
 
 func (f *FluxGo) ConfigLogger(opt LoggerOptions) *FluxGo {
+	f.markProvided(DependencyLogger)
+
 	f.AddDependency(func() *Logger {
+		level := &slog.LevelVar{}
+		level.Set(slog.LevelDebug)
+
 		log := Logger{
-			Logger: otelslog.NewLogger(f.GetCleanName()).With(
+			Logger: slog.New(&levelHandler{Handler: otelslog.NewHandler(f.GetCleanName()), level: level}).With(
 				slog.String("environment", f.Env.Env),
 				slog.String("service.name", f.GetCleanName()),
 				slog.String("service.version", f.Version),
 			),
-			opt: opt,
+			opt:   opt,
+			level: level,
 		}
+		if opt.Level != "" {
+			if err := log.SetLevel(opt.Level); err != nil {
+				f.LogError("LOGGER", fmt.Sprintf("%v, using info", err))
+				level.Set(slog.LevelInfo)
+			}
+		}
+
 		return &log
 	})
-	f.AddInvoke(func(lc fx.Lifecycle, log *Logger, o *Otel) error {
+	f.addResource(func(lc fx.Lifecycle, log *Logger, o *Otel) error {
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				if f.Env.IsTest() {
@@ -112,6 +127,36 @@ func (f *FluxGo) ConfigLogger(opt LoggerOptions) *FluxGo {
 	return f
 }
 
+// SetLevel changes the minimum level of every logger created from f, e.g. from a Config subscription.
+func (f *Logger) SetLevel(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	f.level.Set(l)
+
+	return nil
+}
+func (f *Logger) GetLevel() slog.Level {
+	return f.level.Level()
+}
+
+// levelHandler drops records below a level that can change at runtime.
+type levelHandler struct {
+	slog.Handler
+	level *slog.LevelVar
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.Handler.Enabled(ctx, level)
+}
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
 func (f *Logger) CreateLogger(ctx context.Context) *LoggerInstance {
 	return &LoggerInstance{f.Logger, ctx}
 }
@@ -28,6 +28,8 @@ type Metrics struct {
 }
 
 func (f *FluxGo) AddMetrics() *FluxGo {
+	f.markProvided(DependencyMetrics)
+
 	f.AddDependency(func(o *Otel) *Metrics {
 		metrics := Metrics{
 			counterIntMap:     make(map[string]metric.Int64Counter),
@@ -62,7 +64,7 @@ func (f *FluxGo) AddMetrics() *FluxGo {
 
 		return &metrics
 	})
-	f.AddInvoke(func(lc fx.Lifecycle, m *Metrics) error {
+	f.addResource(func(lc fx.Lifecycle, m *Metrics) error {
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				if err := runtime.Start(runtime.WithMinimumReadMemStatsInterval(10 * time.Second)); err != nil {
@@ -2,8 +2,11 @@ package fluxgo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -57,19 +60,36 @@ type FluxModule struct {
 
 	dependencies []fx.Option
 	invokes      []fx.Option
+	routes       []RouteDefinition
+	commands     []Command
 	swaggerTag   *SwaggerModuleTag
+	hooks        []*moduleHook
+	required     []Dependency
 }
 
 func Module(name string, opts ...ModuleOption) *FluxModule {
-	m := &FluxModule{name, make([]fx.Option, 0), make([]fx.Option, 0), nil}
+	m := &FluxModule{name, make([]fx.Option, 0), make([]fx.Option, 0), make([]RouteDefinition, 0), make([]Command, 0), nil, make([]*moduleHook, 0), make([]Dependency, 0)}
 	for _, opt := range opts {
 		opt.applyModuleOption(m)
 	}
 	return m
 }
 
-func (f *FluxModule) toFx() fx.Option {
-	full := append(f.dependencies, f.invokes...)
+func (f *FluxModule) toFx(flux *FluxGo) fx.Option {
+	full := append([]fx.Option{}, f.dependencies...)
+	full = append(full, f.invokes...)
+
+	for i, hook := range f.hooks {
+		h := hook
+		name := strconv.Quote(fmt.Sprintf("fluxgo_hook.%s.%d", f.Name, i))
+		full = append(full, resolveResult(`name:`+name, h.constructor, func(fn HookFunc) { h.fn = fn }, true))
+	}
+
+	for _, def := range f.routes {
+		if flux.HasRole(def.role()) {
+			full = append(full, def.toFxOption(f))
+		}
+	}
 
 	return fx.Module(f.Name, full...)
 }
@@ -80,9 +100,54 @@ func (f *FluxModule) AddHandler(constructors ...interface{}) *FluxModule {
 	return f
 }
 
+// AddPrivate provides constructors visible only to the module's own handlers, hooks and routes.
+func (f *FluxModule) AddPrivate(constructors ...interface{}) *FluxModule {
+	f.dependencies = append(f.dependencies, fx.Provide(append(constructors, fx.Private)...))
+
+	return f
+}
+
+// SupplyPrivate supplies values, e.g. module configuration, visible only inside the module.
+func (f *FluxModule) SupplyPrivate(values ...interface{}) *FluxModule {
+	f.dependencies = append(f.dependencies, fx.Supply(append(values, fx.Private)...))
+
+	return f
+}
+
+// requirements lists the dependencies declared with Requires plus those implied by enabled routes.
+func (f *FluxModule) requirements(flux *FluxGo) []Dependency {
+	deps := append([]Dependency{}, f.required...)
+	for _, def := range f.routes {
+		if flux.HasRole(def.role()) {
+			deps = append(deps, def.requires()...)
+		}
+	}
+
+	return deps
+}
+
+type requiresOpt struct{ deps []Dependency }
+
+func (o requiresOpt) applyModuleOption(m *FluxModule) {
+	m.required = append(m.required, o.deps...)
+}
+
+// Requires declares subsystems the module needs; the app fails to build when one was not added.
+//
+// Usage: fluxgo.Module("billing", fluxgo.Requires(fluxgo.DependencyDatabase, fluxgo.DependencyRedis))
+func Requires(deps ...Dependency) ModuleOption {
+	return requiresOpt{deps: deps}
+}
+
 type RoutePermission struct {
-	Action  string
-	Subject string
+	Action  string `json:"action"`
+	Subject string `json:"subject"`
+	// Field checks the rules restricted to this field of the subject.
+	Field string `json:"field,omitempty"`
+	// Resource returns what conditional rules are matched against after the request is
+	// parsed, e.g. PermissionOnEntity or a record loaded from the database. Return an error
+	// such as ErrorNotFound rather than nil when there is no resource.
+	Resource PermissionResource `json:"-"`
 }
 
 // RouteDoc holds OpenAPI 3.0 documentation metadata for a single route.
@@ -109,7 +174,25 @@ type RouteIncome struct {
 	CacheTTL        time.Duration
 	CacheInvalidate []string
 	Permission      *RoutePermission
-	Doc             *RouteDoc
+	RateLimit       *RateLimit
+	// FromForm binds a multipart/form-data or urlencoded body, files included, see FormOptions.
+	FromForm bool
+	// Form limits the uploads of FromForm. Default: no limits besides FiberConfig.BodyLimit
+	Form *FormOptions
+	// Pagination configures the PageRequest embedded in Entity. Default: offset pages of 20, no sorting
+	Pagination *PaginationOptions
+	// Idempotent replays the first response to requests repeating its Idempotency-Key header.
+	Idempotent bool
+	// IdempotencyTTL is how long responses are kept for replay. Default: 24h
+	IdempotencyTTL time.Duration
+	// Version is the API version served by the route, see HttpOptions.Versioning.
+	// Routes without a version serve every version.
+	Version string
+	// Timeout cancels the request context and replies 504 once passed; negative disables HttpOptions.RouteTimeout.
+	// Typed handlers still running are then left to finish detached; others are waited for.
+	// A successful result is kept even when returned past the deadline.
+	Timeout time.Duration
+	Doc     *RouteDoc
 }
 type EntityData any
 
@@ -128,82 +211,154 @@ func (f *FluxModule) AddRoute(fn RouteFn) *FluxModule {
 	return f
 }
 
+// Route registers definitions; those whose role is disabled on the FluxGo app are skipped.
 func (f *FluxModule) Route(defs ...RouteDefinition) *FluxModule {
-	for _, def := range defs {
-		f.invokes = append(f.invokes, def.toFxOption(f))
-	}
+	f.routes = append(f.routes, defs...)
 
 	return f
 }
 
+// swaggerTagName registers the Swagger tag of the module and returns the tag of its routes.
+func (m *FluxModule) swaggerTagName(http *Http) string {
+	if m.swaggerTag == nil {
+		return m.Name
+	}
+
+	http.registerModuleTag(m.Name, *m.swaggerTag)
+	if m.swaggerTag.Title != "" {
+		return m.swaggerTag.Title
+	}
+
+	return m.Name
+}
+
 func (m *FluxModule) HttpRoute(f *FluxGo, http *Http, apm *Apm, group string, method string, path string, config RouteIncome, handler HttpHandler) error {
-	tagName := m.Name
-	if m.swaggerTag != nil {
-		http.registerModuleTag(m.Name, *m.swaggerTag)
-		if m.swaggerTag.Title != "" {
-			tagName = m.swaggerTag.Title
-		}
+	return m.httpRoute(f, http, apm, group, method, path, config, routeHandler{http: handler})
+}
+
+func (m *FluxModule) httpRoute(f *FluxGo, http *Http, apm *Apm, group string, method string, path string, config RouteIncome, handler routeHandler) error {
+	tagName := m.swaggerTagName(http)
+	rateLimitStore, err := config.rateLimitStore(http)
+	if err != nil {
+		return fmt.Errorf("%s %s%s: %w", method, group, path, err)
 	}
-	fun := func(c *fiber.Ctx) error {
-		ctx := c.UserContext()
 
-		if config.Permission != nil {
-			role, _ := ctx.Value(RoleContextKey).(string)
-			if role == "" {
-				return c.Status(fiber.StatusUnauthorized).JSON(&GlobalError{
-					Message: "Unauthorized",
-					Code:    "error.unauthorized",
-					Status:  fiber.StatusUnauthorized,
-					Success: false,
-				})
-			}
-			if http.permissions == nil || !http.permissions.Can(role, config.Permission.Action, config.Permission.Subject) {
-				return c.Status(fiber.StatusForbidden).JSON(&GlobalError{
-					Message: "Forbidden",
-					Code:    "error.forbidden",
-					Status:  fiber.StatusForbidden,
-					Success: false,
-				})
-			}
-		}
+	serve := func(c *fiber.Ctx, income interface{}) error {
+		ctx := c.UserContext()
 
 		if cacheRes := config.cache(ctx, f, apm, config, config.cacheKey(c, f.GetCleanName())); cacheRes != nil {
+			if config.Pagination != nil {
+				if info, ok := cachedPageInfo([]byte(*cacheRes)); ok {
+					setPageLinks(c, info)
+				}
+			}
 			return c.Status(200).Send([]byte(*cacheRes))
 		}
 
-		income, err := config.Parse(http, c)
+		res, gErr, err := config.runHandler(c, f, http, apm, handler, income)
 		if err != nil {
-			return c.Status(err.Status).JSON(err)
+			return err
 		}
-
-		res, gErr := handler(c, income)
 		if gErr != nil {
-			return c.Status(gErr.Status).JSON(gErr)
+			return http.SendError(c, gErr)
 		}
 
-		go config.cacheStore(ctx, f, apm, config, config.cacheKey(c, f.GetCleanName()), res)
-		go config.cacheInvalidate(ctx, f, apm, config)
+		key := config.cacheKey(c, f.GetCleanName())
+		f.Go(func() { config.cacheStore(ctx, f, apm, config, key, res) })
+		f.Go(func() { config.cacheInvalidate(ctx, f, apm, config) })
 
 		if res != nil {
+			if page, ok := res.Content.(pageLinker); ok {
+				setPageLinks(c, page.pageInfo())
+			}
 			return c.Status(res.Status).JSON(res.Content)
 		}
 
 		return nil
 	}
 
-	if r := http.GetRouter(group); r == nil {
-		http.app.Add(method, fmt.Sprintf("%s%s", group, path), fun)
-	} else {
-		(*r).Add(method, path, fun)
+	fun := func(c *fiber.Ctx) (resErr error) {
+		defer func() {
+			if r := recover(); r != nil {
+				span := apm.GetSpanFromContext(c.UserContext())
+				span.SetError(fmt.Errorf("panic: %v", r))
+				f.LogError("HTTP", fmt.Sprintf("Recovered panic on %s %s: %v\n%s", method, path, r, debug.Stack()))
+				resErr = http.SendError(c, ErrorInternalError("Internal server error"))
+			}
+		}()
+
+		cancel := config.withTimeout(c, http)
+		defer cancel()
+
+		ctx := c.UserContext()
+
+		if config.RateLimit != nil {
+			if err := config.RateLimit.check(c, f, rateLimitStore, method+":"+group+path); err != nil {
+				return http.SendError(c, err)
+			}
+		}
+
+		if err := http.checkPermission(ctx, config.Permission, nil); err != nil {
+			return http.SendError(c, err)
+		}
+
+		// After a timeout the handler still holds c and closes the files itself.
+		timedOut := false
+		income, err := config.Parse(http, c)
+		defer func() {
+			if !timedOut {
+				closeFormFiles(c)
+			}
+		}()
+		if err != nil {
+			return http.SendError(c, err)
+		}
+
+		// Checked before cached and replayed responses, which would otherwise skip conditional rules.
+		if config.Permission != nil && config.Permission.Resource != nil {
+			resource, err := config.Permission.Resource(c, income)
+			if err != nil {
+				return http.SendError(c, err)
+			}
+			if err := http.checkPermission(ctx, config.Permission, resource); err != nil {
+				return http.SendError(c, err)
+			}
+		}
+
+		var serveErr error
+		if config.Idempotent {
+			serveErr = config.idempotent(c, f, apm, http, method+":"+group+path, func(c *fiber.Ctx) error { return serve(c, income) })
+		} else {
+			serveErr = serve(c, income)
+		}
+		if errors.Is(serveErr, errRouteTimedOut) {
+			timedOut = true
+			return nil
+		}
+
+		return serveErr
+	}
+
+	docPath, err := config.addRoute(http, group, method, path, fun)
+	if err != nil {
+		return err
 	}
 
 	http.addRouteDoc(routeDoc{
 		method:     method,
-		path:       fmt.Sprintf("%s%s", group, path),
+		path:       docPath,
+		version:    config.Version,
+		module:     m.Name,
+		permission: config.Permission,
+		cacheTTL:   config.CacheTTL,
+		rateLimit:  config.RateLimit,
+		idempotent: config.Idempotent,
+		pagination: config.Pagination,
 		tags:       []string{tagName},
 		doc:        config.Doc,
 		entity:     config.Entity,
 		fromBody:   config.FromBody,
+		fromForm:   config.FromForm,
 		fromQuery:  config.FromQuery,
 		fromParam:  config.FromParam,
 		fromHeader: config.FromHeader,
@@ -211,12 +366,60 @@ func (m *FluxModule) HttpRoute(f *FluxGo, http *Http, apm *Apm, group string, me
 
 	return nil
 }
+
+// rateLimitStore validates RateLimit and returns the store counting its requests, nil without one.
+func (i *RouteIncome) rateLimitStore(http *Http) (RateLimitStore, error) {
+	if i.RateLimit == nil {
+		return nil, nil
+	}
+	if err := i.RateLimit.validate(); err != nil {
+		return nil, err
+	}
+	if i.RateLimit.Store != nil {
+		return i.RateLimit.Store, nil
+	}
+
+	return http.memoryRateLimitStore(), nil
+}
+
+// addRoute registers handler under Version when set and returns the documented path.
+func (i *RouteIncome) addRoute(http *Http, group, method, path string, handler fiber.Handler) (string, error) {
+	if i.Version == "" {
+		http.addRoute(group, method, path, handler)
+		return group + path, nil
+	}
+
+	if err := http.addVersionedRoute(group, method, path, i.Version, handler); err != nil {
+		return "", err
+	}
+
+	return group + http.versioning.routePath(path, i.Version), nil
+}
+
+// checkStream rejects the fields a stream cannot honour: it outlives any deadline and its
+// response can be neither cached nor replayed.
+func (i *RouteIncome) checkStream() error {
+	switch {
+	case i.Timeout != 0:
+		return errors.New("Timeout is not supported on streaming routes")
+	case i.Cache != nil || i.CacheTTL != 0 || len(i.CacheInvalidate) > 0:
+		return errors.New("Cache, CacheTTL and CacheInvalidate are not supported on streaming routes")
+	case i.Idempotent:
+		return errors.New("Idempotent is not supported on streaming routes")
+	}
+
+	return nil
+}
+
 func (m *FluxModule) TopicConsume(kafka *Kafka, topic string, handler MessageHandler) error {
 	return kafka.AddConsumer(topic, handler)
 }
 func (m *FluxModule) CronRoute(cron *Cron, crontab string, handler CronHandler) error {
 	return cron.Register(crontab, handler)
 }
+func (m *FluxModule) CronRouteNamed(cron *Cron, name string, crontab string, handler CronHandler) error {
+	return cron.RegisterNamed(name, crontab, handler)
+}
 func (m *FluxModule) GrpcRoute(g *Grpc, handler GrpcHandlerInterface) error {
 	handler.RegisterGrpc(g.server)
 	return nil
@@ -244,6 +447,11 @@ func (i *RouteIncome) Parse(http *Http, c *fiber.Ctx) (EntityData, *GlobalError)
 			}
 		}
 	}
+	if i.FromForm {
+		if err := i.parseForm(c, data); err != nil {
+			return nil, err
+		}
+	}
 	if i.FromQuery {
 		if err := c.QueryParser(data); err != nil {
 			return nil, &GlobalError{
@@ -274,8 +482,17 @@ func (i *RouteIncome) Parse(http *Http, c *fiber.Ctx) (EntityData, *GlobalError)
 			}
 		}
 	}
+	if page, ok := data.(pageRequester); ok {
+		opt := PaginationOptions{}
+		if i.Pagination != nil {
+			opt = *i.Pagination
+		}
+		if err := page.pageRequest().normalize(opt); err != nil {
+			return nil, err
+		}
+	}
 	if i.Validate {
-		if hasErrors, erros := http.GetValidator().Run(data); hasErrors {
+		if hasErrors, erros := http.GetValidator().RunContext(c.UserContext(), data, c.Get(fiber.HeaderAcceptLanguage)); hasErrors {
 			return nil, erros
 		}
 	}
@@ -0,0 +1,116 @@
+package fluxgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type moduleTestConfig struct {
+	Name string
+}
+
+type moduleTestConsumer struct{}
+
+func (h *moduleTestConsumer) HandleMessage(ctx context.Context, data []byte) error {
+	return nil
+}
+
+func TestModule(t *testing.T) {
+	t.Run("Should run hooks by order and stop them in reverse", func(t *testing.T) {
+		calls := []string{}
+		record := func(name string) HookFunc {
+			return func(ctx context.Context) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("second").
+			OnStart(record("start second"), HookOrder(1)).
+			OnStop(record("stop second"), HookOrder(1)))
+		flux.AddModule(Module("first").
+			OnStart(record("start first")).
+			OnStop(record("stop first")))
+
+		app := fx.New(flux.GetFxConfig()...)
+		assert.NoError(t, app.Start(context.Background()))
+		assert.NoError(t, app.Stop(context.Background()))
+
+		assert.Equal(t, []string{"start first", "start second", "stop second", "stop first"}, calls)
+	})
+
+	t.Run("Should resolve hooks with private dependencies", func(t *testing.T) {
+		name := ""
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("billing").
+			SupplyPrivate(moduleTestConfig{Name: "billing"}).
+			OnStart(func(cfg moduleTestConfig) HookFunc {
+				return func(ctx context.Context) error {
+					name = cfg.Name
+					return nil
+				}
+			}))
+
+		app := fx.New(flux.GetFxConfig()...)
+		assert.NoError(t, app.Start(context.Background()))
+		assert.NoError(t, app.Stop(context.Background()))
+
+		assert.Equal(t, "billing", name)
+	})
+
+	t.Run("Should keep private providers inside the module", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("billing").
+			AddPrivate(func() moduleTestConfig { return moduleTestConfig{Name: "billing"} }))
+		flux.AddInvoke(func(cfg moduleTestConfig) {})
+
+		app := fx.New(flux.GetFxConfig()...)
+
+		assert.Error(t, app.Err())
+	})
+
+	t.Run("Should fail when a start hook fails", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("billing").
+			OnStart(func(ctx context.Context) error { return assert.AnError }))
+
+		app := fx.New(flux.GetFxConfig()...)
+		err := app.Start(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Contains(t, err.Error(), "module billing start hook")
+	})
+
+	t.Run("Should fail fast when a route needs a missing dependency", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("orders").
+			AddHandler(func() *moduleTestConsumer { return &moduleTestConsumer{} }).
+			Route(TopicDef[moduleTestConsumer]("orders")))
+
+		app := fx.New(flux.GetFxConfig()...)
+
+		assert.ErrorContains(t, app.Err(), `module "orders" is missing required dependencies: kafka (call FluxGo.AddKafka), apm (call FluxGo.AddApm)`)
+	})
+
+	t.Run("Should fail fast when a declared dependency is missing", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("reports", Requires(DependencyDatabase)))
+
+		app := fx.New(flux.GetFxConfig()...)
+
+		assert.ErrorContains(t, app.Err(), "database (call FluxGo.AddDatabase)")
+	})
+
+	t.Run("Should accept dependencies given to AddSupply", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddSupply(&Redis{})
+		flux.AddModule(Module("sessions", Requires(DependencyRedis)))
+
+		assert.NoError(t, fx.New(flux.GetFxConfig()...).Err())
+	})
+}
@@ -44,7 +44,7 @@ func (f *FluxGo) addOtel(opt OtelOptions) *FluxGo {
 	f.AddDependency(func() *Otel {
 		return &otel
 	})
-	f.AddInvoke(func(lc fx.Lifecycle, o *Otel) error {
+	f.addResource(func(lc fx.Lifecycle, o *Otel) error {
 		lc.Append(fx.Hook{
 			OnStop: func(ctx context.Context) error {
 				if o.grpcConnection != nil {
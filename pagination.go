@@ -0,0 +1,385 @@
+package fluxgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+)
+
+// PaginationMode is how a list route walks its pages.
+type PaginationMode string
+
+const (
+	// PageByOffset addresses pages by number and reports the total.
+	PageByOffset PaginationMode = "offset"
+	// PageByCursor continues after the last row of the previous page, which stays stable while
+	// rows are inserted and does not count the total.
+	PageByCursor PaginationMode = "cursor"
+
+	defaultPageLimit    = 20
+	defaultPageMaxLimit = 100
+)
+
+// PaginationOptions configures the PageRequest of a list route.
+type PaginationOptions struct {
+	// Mode default: PageByOffset
+	Mode PaginationMode
+	// DefaultLimit default: 20
+	DefaultLimit int
+	// MaxLimit caps the limit asked by the client. Default: 100
+	MaxLimit int
+	// SortFields are the fields the client may sort by; any other is rejected.
+	SortFields []string
+	// DefaultSort applies without a sort parameter, e.g. "-created_at".
+	DefaultSort string
+}
+
+// PageRequest is embedded in the entity of list routes reading FromQuery. The route fills in
+// the defaults of RouteIncome.Pagination and rejects sorts outside its SortFields, so
+// Repository.FindPage can apply it directly.
+type PageRequest struct {
+	Page   int    `query:"page" json:"page,omitempty" jsonschema:"description=Page number of offset pagination,minimum=1"`
+	Limit  int    `query:"limit" json:"limit,omitempty" jsonschema:"description=Number of items per page,minimum=1"`
+	Cursor string `query:"cursor" json:"cursor,omitempty" jsonschema:"description=Next cursor of the previous page"`
+	Sort   string `query:"sort" json:"sort,omitempty" jsonschema:"description=Comma-separated fields; prefix with - to sort descending"`
+	// Mode is set from RouteIncome.Pagination.
+	Mode PaginationMode `query:"-" json:"-"`
+}
+
+// SortField is a field of PageRequest.Sort.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Paginated is the response envelope of list routes. HTTP routes answering it set the
+// first, prev, next and last Link headers.
+type Paginated[T any] struct {
+	Data []T `json:"data"`
+	// Page is the number of the page in PageByOffset mode.
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit"`
+	// Total is the number of items in PageByOffset mode.
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// pageRequester is implemented by entities embedding PageRequest.
+type pageRequester interface {
+	pageRequest() *PageRequest
+}
+
+func (p *PageRequest) pageRequest() *PageRequest {
+	return p
+}
+
+// SortFields returns the fields of Sort in order.
+func (p PageRequest) SortFields() []SortField {
+	fields := []SortField{}
+	for _, part := range strings.Split(p.Sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, desc := strings.CutPrefix(part, "-")
+		fields = append(fields, SortField{Field: strings.TrimPrefix(field, "+"), Desc: desc})
+	}
+
+	return fields
+}
+
+// Offset is the number of rows before the page in PageByOffset mode.
+func (p PageRequest) Offset() int {
+	return (max(p.Page, 1) - 1) * p.Limit
+}
+
+// applyDefaults fills in the limit, page and mode missing from p.
+func (p *PageRequest) applyDefaults(opt PaginationOptions) {
+	maxLimit := opt.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = defaultPageMaxLimit
+	}
+	if p.Limit <= 0 {
+		p.Limit = opt.DefaultLimit
+		if p.Limit <= 0 {
+			p.Limit = defaultPageLimit
+		}
+	}
+	p.Limit = min(p.Limit, maxLimit)
+	p.Page = max(p.Page, 1)
+
+	if p.Mode == "" {
+		p.Mode = opt.Mode
+	}
+	if p.Mode == "" {
+		p.Mode = PageByOffset
+	}
+}
+
+// normalize applies the options of a route to p and validates its sort and cursor.
+func (p *PageRequest) normalize(opt PaginationOptions) *GlobalError {
+	p.Mode = ""
+	p.applyDefaults(opt)
+
+	if p.Sort == "" {
+		p.Sort = opt.DefaultSort
+	}
+	allowed := slices.Clone(opt.SortFields)
+	for _, field := range (PageRequest{Sort: opt.DefaultSort}).SortFields() {
+		allowed = append(allowed, field.Field)
+	}
+	sorts := p.SortFields()
+	for _, field := range sorts {
+		if !slices.Contains(allowed, field.Field) {
+			return ErrorBadRequest(fmt.Sprintf("Cannot sort by %s", field.Field), "error.invalid_sort")
+		}
+		// Keyset conditions compare every sort field at once, so they share one direction.
+		if p.Mode == PageByCursor && field.Desc != sorts[0].Desc {
+			return ErrorBadRequest("Cursor pagination requires every sort field in the same direction", "error.invalid_sort")
+		}
+	}
+
+	if p.Mode == PageByCursor && p.Cursor != "" {
+		if _, err := decodeCursor(p.Cursor); err != nil {
+			return ErrorBadRequest("Invalid cursor", "error.invalid_cursor")
+		}
+	}
+
+	return nil
+}
+
+// encodeCursor encodes the sort values of the last row of a page.
+func encodeCursor(values []any) (string, error) {
+	content, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+func decodeCursor(cursor string) ([]any, error) {
+	content, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers stay json.Number, sent to the database as text so integers are not mangled.
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var values []any
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// pageQuery builds the query of a page of table. where is a SQL condition using the
+// placeholders $1..$n for args; the keyset condition of a cursor continues after them.
+// It selects one row more than the limit to tell whether there are more.
+func pageQuery(table, primaryKey string, page PageRequest, where string, args []any) (string, []any, []SortField, error) {
+	sorts := page.SortFields()
+	if !slices.ContainsFunc(sorts, func(sort SortField) bool { return sort.Field == primaryKey }) {
+		desc := len(sorts) > 0 && sorts[len(sorts)-1].Desc
+		sorts = append(sorts, SortField{Field: primaryKey, Desc: desc})
+	}
+
+	conditions := []string{}
+	if where != "" {
+		conditions = append(conditions, "("+where+")")
+	}
+	args = slices.Clone(args)
+
+	if page.Mode == PageByCursor && page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		if len(values) != len(sorts) {
+			return "", nil, nil, fmt.Errorf("invalid cursor: expected %d values, got %d", len(sorts), len(values))
+		}
+
+		columns := make([]string, 0, len(sorts))
+		placeholders := make([]string, 0, len(sorts))
+		for i, sort := range sorts {
+			if sort.Desc != sorts[0].Desc {
+				return "", nil, nil, fmt.Errorf("cursor pagination requires every sort field in the same direction")
+			}
+			columns = append(columns, pq.QuoteIdentifier(sort.Field))
+			args = append(args, values[i])
+			placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+		}
+
+		operator := ">"
+		if sorts[0].Desc {
+			operator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operator, strings.Join(placeholders, ", ")))
+	}
+
+	order := make([]string, 0, len(sorts))
+	for _, sort := range sorts {
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		order = append(order, pq.QuoteIdentifier(sort.Field)+" "+direction)
+	}
+
+	query := "SELECT * FROM " + pq.QuoteIdentifier(table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %d", strings.Join(order, ", "), page.Limit+1)
+	if page.Mode != PageByCursor && page.Offset() > 0 {
+		query += fmt.Sprintf(" OFFSET %d", page.Offset())
+	}
+
+	return query, args, sorts, nil
+}
+
+// FindPage selects a page of the rows matching where, a SQL condition using the placeholders
+// $1..$n for args ("" matches every row). The primary key breaks ties between sorted rows.
+// In PageByOffset mode it also counts the matching rows.
+func (o *Repository[T]) FindPage(ctx context.Context, page PageRequest, where string, args ...any) (*Paginated[T], error) {
+	ctx, span := o.StartSpan(ctx)
+	defer span.End()
+
+	page.applyDefaults(PaginationOptions{})
+
+	query, queryArgs, sorts, err := pageQuery(o.TableName, o.PrimaryKey, page, where, args)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	db := o.DB.ReadOnlyDB()
+
+	rows := make([]T, 0, page.Limit+1)
+	if err := db.SelectContext(ctx, &rows, query, queryArgs...); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	result := &Paginated[T]{Data: rows, Limit: page.Limit, HasMore: len(rows) > page.Limit}
+	if result.HasMore {
+		result.Data = rows[:page.Limit]
+	}
+
+	if page.Mode == PageByCursor {
+		if result.HasMore {
+			last := reflect.ValueOf(result.Data[len(result.Data)-1])
+			fields := db.Mapper.TypeMap(last.Type())
+
+			values := make([]any, 0, len(sorts))
+			for _, sort := range sorts {
+				field := fields.GetByPath(sort.Field)
+				if field == nil {
+					err := fmt.Errorf("cannot build cursor: %T has no column %s", result.Data[0], sort.Field)
+					span.SetError(err)
+					return nil, err
+				}
+				values = append(values, last.FieldByIndex(field.Index).Interface())
+			}
+
+			if result.NextCursor, err = encodeCursor(values); err != nil {
+				span.SetError(err)
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	countQuery := "SELECT COUNT(*) FROM " + pq.QuoteIdentifier(o.TableName)
+	if where != "" {
+		countQuery += " WHERE " + where
+	}
+
+	var total int64
+	if err := db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result.Page = page.Page
+	result.Total = &total
+
+	return result, nil
+}
+
+// pageInfo is what Link headers are built from.
+type pageInfo struct {
+	page       int
+	limit      int
+	total      *int64
+	nextCursor string
+	hasMore    bool
+}
+
+// pageLinker is implemented by Paginated responses.
+type pageLinker interface {
+	pageInfo() pageInfo
+}
+
+func (p Paginated[T]) pageInfo() pageInfo {
+	return pageInfo{page: p.Page, limit: p.Limit, total: p.Total, nextCursor: p.NextCursor, hasMore: p.HasMore}
+}
+
+// cachedPageInfo reads the page info back from a cached Paginated response.
+func cachedPageInfo(content []byte) (pageInfo, bool) {
+	var page Paginated[json.RawMessage]
+	if err := json.Unmarshal(content, &page); err != nil || page.Limit <= 0 {
+		return pageInfo{}, false
+	}
+
+	return page.pageInfo(), true
+}
+
+// setPageLinks sets the RFC 8288 Link header of a page, keeping the other query parameters.
+func setPageLinks(c *fiber.Ctx, info pageInfo) {
+	link := func(rel string, set map[string]string) string {
+		query := c.Request().URI().QueryArgs()
+		args := fiber.AcquireArgs()
+		defer fiber.ReleaseArgs(args)
+		query.CopyTo(args)
+		for key, value := range set {
+			args.Set(key, value)
+		}
+
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, c.Path(), args.String(), rel)
+	}
+
+	links := []string{}
+	if info.nextCursor != "" {
+		links = append(links, link("next", map[string]string{"cursor": info.nextCursor}))
+	} else if info.page > 0 {
+		limit := strconv.Itoa(info.limit)
+		links = append(links, link("first", map[string]string{"page": "1", "limit": limit}))
+		if info.page > 1 {
+			links = append(links, link("prev", map[string]string{"page": strconv.Itoa(info.page - 1), "limit": limit}))
+		}
+		if info.hasMore {
+			links = append(links, link("next", map[string]string{"page": strconv.Itoa(info.page + 1), "limit": limit}))
+		}
+		if info.total != nil && info.limit > 0 {
+			last := max(int(math.Ceil(float64(*info.total)/float64(info.limit))), 1)
+			links = append(links, link("last", map[string]string{"page": strconv.Itoa(last), "limit": limit}))
+		}
+	}
+
+	if len(links) > 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,170 @@
+package fluxgo
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type paginationTestReq struct {
+	PageRequest
+	Name string `query:"name"`
+}
+
+type paginationTestItem struct {
+	Limit int    `json:"limit"`
+	Sort  string `json:"sort"`
+}
+
+type paginationTestHandler struct{}
+
+func (h *paginationTestHandler) Handle(ctx context.Context, req *paginationTestReq) (*Paginated[paginationTestItem], *GlobalError) {
+	total := int64(95)
+
+	return &Paginated[paginationTestItem]{
+		Data:    []paginationTestItem{{Limit: req.Limit, Sort: req.Sort}},
+		Page:    req.Page,
+		Limit:   req.Limit,
+		Total:   &total,
+		HasMore: req.Offset()+req.Limit < int(total),
+	}, nil
+}
+
+func TestPagination(t *testing.T) {
+	cache := &memoryTestCache{values: map[string]string{}}
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *paginationTestHandler { return &paginationTestHandler{} }).
+		Route(
+			TypedGET[paginationTestHandler, paginationTestReq, Paginated[paginationTestItem]]("/api", "/items", RouteIncome{
+				FromQuery:  true,
+				Pagination: &PaginationOptions{MaxLimit: 50, SortFields: []string{"name"}, DefaultSort: "-created_at"},
+			}),
+			TypedGET[paginationTestHandler, paginationTestReq, Paginated[paginationTestItem]]("/api", "/cached-items", RouteIncome{
+				FromQuery:  true,
+				Pagination: &PaginationOptions{SortFields: []string{"name"}},
+				Cache:      cache,
+				CacheTTL:   time.Minute,
+			}),
+			TypedGET[paginationTestHandler, paginationTestReq, Paginated[paginationTestItem]]("/api", "/feed", RouteIncome{
+				FromQuery:  true,
+				Pagination: &PaginationOptions{Mode: PageByCursor, SortFields: []string{"name"}, DefaultSort: "-created_at"},
+			}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should apply the route defaults and limits", func(t *testing.T) {
+		status, body := RunTestRequest(http, "GET", "/api/items?limit=500", nil, nil)
+		assert.Equal(t, 200, status)
+
+		item := body["data"].([]any)[0].(map[string]any)
+		assert.Equal(t, float64(50), item["limit"])
+		assert.Equal(t, "-created_at", item["sort"])
+		assert.Equal(t, float64(1), body["page"])
+		assert.Equal(t, float64(95), body["total"])
+	})
+
+	t.Run("Should reject sort fields outside the whitelist", func(t *testing.T) {
+		status, body := RunTestRequest(http, "GET", "/api/items?sort=password", nil, nil)
+		assert.Equal(t, 400, status)
+		assert.Equal(t, "error.invalid_sort", body["code"])
+
+		status, _ = RunTestRequest(http, "GET", "/api/items?sort=-name,created_at", nil, nil)
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should reject mixed sort directions in cursor mode", func(t *testing.T) {
+		status, body := RunTestRequest(http, "GET", "/api/feed?sort=name,-created_at", nil, nil)
+		assert.Equal(t, 400, status)
+		assert.Equal(t, "error.invalid_sort", body["code"])
+
+		status, _ = RunTestRequest(http, "GET", "/api/feed?sort=-name,-created_at", nil, nil)
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should set Link headers keeping the other query parameters", func(t *testing.T) {
+		res, err := http.GetApp().Test(httptest.NewRequest("GET", "/api/items?page=2&limit=10&name=john", nil))
+		assert.NoError(t, err)
+
+		link := res.Header.Get("Link")
+		assert.Contains(t, link, `</api/items?page=1&limit=10&name=john>; rel="first"`)
+		assert.Contains(t, link, `</api/items?page=1&limit=10&name=john>; rel="prev"`)
+		assert.Contains(t, link, `</api/items?page=3&limit=10&name=john>; rel="next"`)
+		assert.Contains(t, link, `</api/items?page=10&limit=10&name=john>; rel="last"`)
+	})
+
+	t.Run("Should set Link headers on cache hits", func(t *testing.T) {
+		res, err := http.GetApp().Test(httptest.NewRequest("GET", "/api/cached-items?page=2&limit=10", nil))
+		assert.NoError(t, err)
+		link := res.Header.Get("Link")
+		assert.Contains(t, link, `rel="next"`)
+
+		assert.Eventually(t, func() bool {
+			cache.mu.Lock()
+			defer cache.mu.Unlock()
+			return len(cache.values) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		res, err = http.GetApp().Test(httptest.NewRequest("GET", "/api/cached-items?page=2&limit=10", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, link, res.Header.Get("Link"))
+	})
+
+	t.Run("Should document the query parameters and envelope", func(t *testing.T) {
+		spec := http.OpenAPISpec("")
+		operation := spec["paths"].(map[string]any)["/api/items"].(map[string]any)["get"].(map[string]any)
+
+		params := map[string]map[string]any{}
+		for _, p := range operation["parameters"].([]any) {
+			param := p.(map[string]any)
+			params[param["name"].(string)] = param
+		}
+		assert.Equal(t, 50, params["limit"]["schema"].(map[string]any)["maximum"])
+		assert.Contains(t, params["sort"]["description"], "name, created_at")
+		assert.NotContains(t, params, "cursor")
+
+		ok := operation["responses"].(map[string]any)["200"].(map[string]any)
+		assert.Contains(t, ok["headers"], "Link")
+		schema := ok["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+		assert.Contains(t, schema["properties"], "has_more")
+	})
+}
+
+func TestPageQuery(t *testing.T) {
+	t.Run("Should select an offset page ordered by the sort fields and primary key", func(t *testing.T) {
+		page := PageRequest{Page: 3, Limit: 10, Sort: "-created_at", Mode: PageByOffset}
+
+		query, args, _, err := pageQuery("users", "id", page, "tenant_id = $1", []any{"t1"})
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM "users" WHERE (tenant_id = $1) ORDER BY "created_at" DESC, "id" DESC LIMIT 11 OFFSET 20`, query)
+		assert.Equal(t, []any{"t1"}, args)
+	})
+
+	t.Run("Should continue after the cursor", func(t *testing.T) {
+		cursor, err := encodeCursor([]any{"2024-01-01T00:00:00Z", 42})
+		assert.NoError(t, err)
+		page := PageRequest{Limit: 5, Sort: "created_at", Cursor: cursor, Mode: PageByCursor}
+
+		query, args, sorts, err := pageQuery("users", "id", page, "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM "users" WHERE ("created_at", "id") > ($1, $2) ORDER BY "created_at" ASC, "id" ASC LIMIT 6`, query)
+		assert.Equal(t, "2024-01-01T00:00:00Z", args[0])
+		assert.Equal(t, "42", args[1].(interface{ String() string }).String())
+		assert.Equal(t, []SortField{{Field: "created_at"}, {Field: "id"}}, sorts)
+	})
+
+	t.Run("Should reject cursors over mixed sort directions", func(t *testing.T) {
+		cursor, _ := encodeCursor([]any{"a", 1})
+		page := PageRequest{Limit: 5, Sort: "-name,id", Cursor: cursor, Mode: PageByCursor}
+
+		_, _, _, err := pageQuery("users", "id", page, "", nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,418 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PermissionRule grants, or with Inverted denies, Action on Subject. It serializes like
+// a CASL raw rule so the frontend can share it.
+type PermissionRule struct {
+	Action  string `json:"action"`
+	Subject string `json:"subject"`
+	// Conditions restrict the rule to resources whose fields match, e.g. {"owner_id": "${subject}"}.
+	// Values are compared for equality or with the operators $eq, $ne, $in, $nin, $gt, $gte, $lt
+	// and $lte. Nested fields use dots. ${role}, ${subject}, ${tenant} and ${claims.<path>} are
+	// replaced with the values of the request context.
+	Conditions map[string]any `json:"conditions,omitempty"`
+	// Fields restrict the rule to these fields of the subject; empty applies to every field.
+	Fields   []string `json:"fields,omitempty"`
+	Inverted bool     `json:"inverted,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	// Inherits includes every rule of another role in place of this one, e.g. {Inherits: "editor"}.
+	Inherits string `json:"inherits,omitempty"`
+}
+
+// Permissions maps each role to its rules. Later rules take precedence over earlier ones,
+// so inverted rules usually come last.
+type Permissions map[string][]PermissionRule
+
+// PermissionCheck is an action on a subject, optionally on a resource or one of its fields.
+type PermissionCheck struct {
+	Action  string
+	Subject string
+	// Resource is matched against the rule conditions. When nil, conditional rules allow
+	// the action since some resource may match, and conditional inverted rules are ignored.
+	Resource any
+	Field    string
+}
+
+// Get returns the rules of role with the inherited roles expanded.
+func (perm Permissions) Get(role string) []PermissionRule {
+	return perm.expand(role, map[string]bool{})
+}
+
+func (perm Permissions) expand(role string, visited map[string]bool) []PermissionRule {
+	if visited[role] {
+		return []PermissionRule{}
+	}
+	visited[role] = true
+	defer delete(visited, role)
+
+	rules := []PermissionRule{}
+	for _, rule := range perm[role] {
+		if rule.Inherits != "" {
+			rules = append(rules, perm.expand(rule.Inherits, visited)...)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// resolve expands the inherited roles of every role once, so checks do not repeat it.
+func (perm Permissions) resolve() Permissions {
+	resolved := Permissions{}
+	for role := range perm {
+		resolved[role] = perm.Get(role)
+	}
+
+	return resolved
+}
+
+func (perm Permissions) Can(role, action, subject string) bool {
+	return perm.Check(context.Background(), role, PermissionCheck{Action: action, Subject: subject})
+}
+
+// Check reports whether role can perform check; ctx supplies the ${...} values of conditions.
+// The last matching rule decides, and nothing is allowed without one.
+func (perm Permissions) Check(ctx context.Context, role string, check PermissionCheck) bool {
+	rules := perm.Get(role)
+
+	var resource map[string]any
+	if check.Resource != nil {
+		resource, _ = normalizePermissionValue(check.Resource).(map[string]any)
+		if resource == nil {
+			resource = map[string]any{}
+		}
+	}
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+		if !rule.matches(check) {
+			continue
+		}
+
+		if len(rule.Conditions) > 0 {
+			if resource == nil {
+				if rule.Inverted {
+					continue
+				}
+				return true
+			}
+			if !matchConditions(resource, interpolateConditions(ctx, rule.Conditions)) {
+				continue
+			}
+		}
+
+		return !rule.Inverted
+	}
+
+	return false
+}
+
+func (rule PermissionRule) matches(check PermissionCheck) bool {
+	if rule.Action != check.Action && rule.Action != "manage" {
+		return false
+	}
+	if rule.Subject != check.Subject && rule.Subject != "all" {
+		return false
+	}
+	if len(rule.Fields) == 0 {
+		return true
+	}
+	// Denying some fields does not deny the subject as a whole.
+	if check.Field == "" {
+		return !rule.Inverted
+	}
+
+	return slices.Contains(rule.Fields, check.Field)
+}
+
+// interpolateRules returns the rules with the ${...} placeholders of their conditions replaced.
+func interpolateRules(ctx context.Context, rules []PermissionRule) []PermissionRule {
+	interpolated := make([]PermissionRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule.Conditions) > 0 {
+			rule.Conditions = interpolateConditions(ctx, rule.Conditions)
+		}
+		interpolated = append(interpolated, rule)
+	}
+
+	return interpolated
+}
+
+func interpolateConditions(ctx context.Context, conditions map[string]any) map[string]any {
+	return interpolateValue(ctx, conditions).(map[string]any)
+}
+
+func interpolateValue(ctx context.Context, value any) any {
+	switch v := value.(type) {
+	case string:
+		name, found := strings.CutPrefix(v, "${")
+		if !found || !strings.HasSuffix(name, "}") {
+			return v
+		}
+		if value := contextPlaceholder(ctx, strings.TrimSuffix(name, "}")); value != nil {
+			return value
+		}
+		return unresolvedPlaceholder(v)
+	case map[string]any:
+		interpolated := make(map[string]any, len(v))
+		for key, item := range v {
+			interpolated[key] = interpolateValue(ctx, item)
+		}
+		return interpolated
+	case []any:
+		interpolated := make([]any, 0, len(v))
+		for _, item := range v {
+			interpolated = append(interpolated, interpolateValue(ctx, item))
+		}
+		return interpolated
+	}
+
+	return value
+}
+
+func contextPlaceholder(ctx context.Context, name string) any {
+	switch name {
+	case "role":
+		return ctx.Value(RoleContextKey)
+	case "subject":
+		return ctx.Value(SubjectContextKey)
+	case "tenant":
+		return ctx.Value(TenantContextKey)
+	}
+
+	if path, found := strings.CutPrefix(name, "claims."); found {
+		if claims, ok := ctx.Value(ClaimsContextKey).(jwt.MapClaims); ok {
+			return lookupPath(claims, path)
+		}
+	}
+
+	return nil
+}
+
+// unresolvedPlaceholder is a ${...} placeholder missing from the request context.
+// Conditions using it never match, and it serializes as written.
+type unresolvedPlaceholder string
+
+func matchConditions(resource map[string]any, conditions map[string]any) bool {
+	for path, expected := range conditions {
+		if hasUnresolvedPlaceholder(expected) {
+			return false
+		}
+
+		actual := lookupPath(resource, path)
+		expected = normalizePermissionValue(expected)
+
+		operators, isOperator := expected.(map[string]any)
+		if !isOperator || !hasOperatorKeys(operators) {
+			if !permissionEqual(actual, expected) {
+				return false
+			}
+			continue
+		}
+
+		for operator, operand := range operators {
+			if !matchOperator(operator, actual, operand) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func hasUnresolvedPlaceholder(value any) bool {
+	switch v := value.(type) {
+	case unresolvedPlaceholder:
+		return true
+	case map[string]any:
+		for _, item := range v {
+			if hasUnresolvedPlaceholder(item) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(v, hasUnresolvedPlaceholder)
+	}
+
+	return false
+}
+
+func hasOperatorKeys(value map[string]any) bool {
+	for key := range value {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+
+	return len(value) > 0
+}
+
+func matchOperator(operator string, actual, operand any) bool {
+	switch operator {
+	case "$eq":
+		return permissionEqual(actual, operand)
+	case "$ne":
+		return !permissionEqual(actual, operand)
+	case "$in", "$nin":
+		values, _ := operand.([]any)
+		found := slices.ContainsFunc(values, func(value any) bool { return permissionEqual(actual, value) })
+		return found == (operator == "$in")
+	case "$gt", "$gte", "$lt", "$lte":
+		a, aOk := permissionNumber(actual)
+		b, bOk := permissionNumber(operand)
+		if !aOk || !bOk {
+			return false
+		}
+		switch operator {
+		case "$gt":
+			return a > b
+		case "$gte":
+			return a >= b
+		case "$lt":
+			return a < b
+		}
+		return a <= b
+	}
+
+	return false
+}
+
+// permissionEqual compares a resource value with a condition. Placeholders such as ${subject}
+// resolve to strings while numeric ids of the resource are numbers, so a number matches a
+// numeric string of the same value.
+func permissionEqual(actual, expected any) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+
+	_, actualIsNumber := actual.(float64)
+	_, expectedIsNumber := expected.(float64)
+	if !actualIsNumber && !expectedIsNumber {
+		return false
+	}
+
+	a, aOk := permissionNumber(actual)
+	b, bOk := permissionNumber(expected)
+
+	return aOk && bOk && a == b
+}
+
+// permissionNumber reads numbers and numeric strings.
+func permissionNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+func lookupPath(value map[string]any, path string) any {
+	var current any = value
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = object[part]
+	}
+
+	return current
+}
+
+// normalizePermissionValue converts a value to its JSON form, so structs are matched by
+// their json field names and every number is a float64.
+func normalizePermissionValue(value any) any {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var normalized any
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return nil
+	}
+
+	return normalized
+}
+
+// PermissionResource returns the value a route permission is checked against, see RoutePermission.Resource.
+type PermissionResource func(c *fiber.Ctx, income interface{}) (any, *GlobalError)
+
+// PermissionOnEntity checks the route permission against the parsed request entity.
+func PermissionOnEntity() PermissionResource {
+	return func(c *fiber.Ctx, income interface{}) (any, *GlobalError) {
+		return income, nil
+	}
+}
+
+func (h *Http) GetPermissions(ctx context.Context) []PermissionRule {
+	if role := ctx.Value(RoleContextKey); role != nil {
+		if permissions := h.permissions.Load(); permissions != nil {
+			return interpolateRules(ctx, permissions.Get(role.(string)))
+		}
+	}
+
+	return nil
+}
+
+// SetPermissions atomically replaces the role permissions used by every route.
+func (h *Http) SetPermissions(permissions Permissions) {
+	resolved := permissions.resolve()
+	h.permissions.Store(&resolved)
+}
+
+// Can reports whether the role of the request context can perform check, e.g. on a
+// resource loaded by the handler.
+func (h *Http) Can(ctx context.Context, check PermissionCheck) bool {
+	role, _ := ctx.Value(RoleContextKey).(string)
+	permissions := h.permissions.Load()
+
+	return role != "" && permissions != nil && permissions.Check(ctx, role, check)
+}
+
+// checkPermission returns the 401/403 error for a request whose role cannot perform perm
+// on resource. A nil perm always passes.
+func (h *Http) checkPermission(ctx context.Context, perm *RoutePermission, resource any) *GlobalError {
+	if perm == nil {
+		return nil
+	}
+
+	if role, _ := ctx.Value(RoleContextKey).(string); role == "" {
+		return errorUnauthorized()
+	}
+	if !h.Can(ctx, PermissionCheck{Action: perm.Action, Subject: perm.Subject, Field: perm.Field, Resource: resource}) {
+		return &GlobalError{
+			Message: "Forbidden",
+			Code:    "error.forbidden",
+			Status:  fiber.StatusForbidden,
+			Success: false,
+		}
+	}
+
+	return nil
+}
+
+func (perm *RoutePermission) String() string {
+	if perm.Field != "" {
+		return fmt.Sprintf("%s:%s.%s", perm.Action, perm.Subject, perm.Field)
+	}
+
+	return fmt.Sprintf("%s:%s", perm.Action, perm.Subject)
+}
@@ -0,0 +1,339 @@
+package fluxgo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"go.uber.org/fx"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultPermissionsAdminPath = "/_fluxgo/permissions"
+
+// PermissionSource loads the role permissions, see FluxGo.AddPermissionSource.
+type PermissionSource interface {
+	LoadPermissions(ctx context.Context) (Permissions, error)
+}
+
+// PermissionWatcher is implemented by sources that notify changes; WatchPermissions
+// calls notify on every change until ctx is cancelled.
+type PermissionWatcher interface {
+	WatchPermissions(ctx context.Context, notify func()) error
+}
+
+type PermissionSourceOptions struct {
+	// ReloadInterval reloads the permissions periodically. Zero only reloads on
+	// notifications and Http.ReloadPermissions.
+	ReloadInterval time.Duration
+	// Admin exposes the effective rules of every role over HTTP.
+	Admin *PermissionsAdminOptions
+}
+
+// PermissionsAdminOptions configures the endpoint listing the loaded permissions.
+type PermissionsAdminOptions struct {
+	// Path of the endpoint. Default: "/_fluxgo/permissions"
+	Path string
+	// Permission required to read the rules, checked like RouteIncome.Permission. Required,
+	// since the rules and their conditions describe the whole access model.
+	Permission *RoutePermission
+	// Middleware runs before the endpoint, e.g. to authenticate the caller.
+	Middleware []fiber.Handler
+}
+
+// PermissionsSnapshot is served by the admin endpoint.
+type PermissionsSnapshot struct {
+	Roles    Permissions `json:"roles"`
+	LoadedAt time.Time   `json:"loaded_at"`
+}
+
+// FilePermissionSource reads a YAML or JSON file mapping each role to its rules.
+type FilePermissionSource struct {
+	Path string
+}
+
+func (s FilePermissionSource) LoadPermissions(ctx context.Context) (Permissions, error) {
+	content, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permissions: %w", err)
+	}
+
+	var data any
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	case ".json":
+		err = json.Unmarshal(content, &data)
+	default:
+		return nil, fmt.Errorf("unsupported permissions file format: %s", s.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid permissions file %s: %w", s.Path, err)
+	}
+
+	// Decoding through JSON applies the json tags of PermissionRule to YAML too.
+	content, err = json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permissions file %s: %w", s.Path, err)
+	}
+
+	permissions := Permissions{}
+	if err := json.Unmarshal(content, &permissions); err != nil {
+		return nil, fmt.Errorf("invalid permissions file %s: %w", s.Path, err)
+	}
+
+	return permissions, nil
+}
+
+// DatabasePermissionSource reads the rules from a table, in id order:
+//
+//	CREATE TABLE permissions (
+//		id         SERIAL PRIMARY KEY,
+//		role       TEXT NOT NULL,
+//		action     TEXT NOT NULL DEFAULT '',
+//		subject    TEXT NOT NULL DEFAULT '',
+//		conditions JSONB,
+//		fields     JSONB,
+//		inverted   BOOLEAN NOT NULL DEFAULT FALSE,
+//		reason     TEXT NOT NULL DEFAULT '',
+//		inherits   TEXT NOT NULL DEFAULT ''
+//	);
+//
+// With Channel set, a NOTIFY on it reloads the permissions, e.g. from a trigger on the table.
+type DatabasePermissionSource struct {
+	// Database is the name given to FluxGo.AddDatabase. Default: "default"
+	Database string
+	// Table default: "permissions"
+	Table   string
+	Channel string
+	// ListenDsn is the connection used to LISTEN on Channel, required with it.
+	ListenDsn string
+
+	db *Database
+}
+
+func (s *DatabasePermissionSource) bindPermissionSource(f *FluxGo) error {
+	if s.Channel != "" && s.ListenDsn == "" {
+		return fmt.Errorf("DatabasePermissionSource.ListenDsn is required to listen on %s", s.Channel)
+	}
+	s.db = f.db
+
+	return nil
+}
+
+func (s *DatabasePermissionSource) LoadPermissions(ctx context.Context) (Permissions, error) {
+	name := defaultString(s.Database, "default")
+	table := pq.QuoteIdentifier(defaultString(s.Table, "permissions"))
+
+	ctx, span := s.db.StartSpan(ctx, "permissions/load")
+	defer span.End()
+
+	rows, err := s.db.ReadOnlyDBNamed(name).QueryContext(ctx, fmt.Sprintf(
+		"SELECT role, action, subject, conditions, fields, inverted, reason, inherits FROM %s ORDER BY id", table))
+	if err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := Permissions{}
+	for rows.Next() {
+		var role string
+		var rule PermissionRule
+		var conditions, fields sql.NullString
+
+		if err := rows.Scan(&role, &rule.Action, &rule.Subject, &conditions, &fields, &rule.Inverted, &rule.Reason, &rule.Inherits); err != nil {
+			span.SetError(err)
+			return nil, fmt.Errorf("failed to load permissions: %w", err)
+		}
+		if conditions.Valid {
+			if err := json.Unmarshal([]byte(conditions.String), &rule.Conditions); err != nil {
+				return nil, fmt.Errorf("invalid conditions for role %s: %w", role, err)
+			}
+		}
+		if fields.Valid {
+			if err := json.Unmarshal([]byte(fields.String), &rule.Fields); err != nil {
+				return nil, fmt.Errorf("invalid fields for role %s: %w", role, err)
+			}
+		}
+
+		permissions[role] = append(permissions[role], rule)
+	}
+
+	return permissions, rows.Err()
+}
+
+func (s *DatabasePermissionSource) WatchPermissions(ctx context.Context, notify func()) error {
+	if s.Channel == "" {
+		<-ctx.Done()
+		return nil
+	}
+
+	listener := pq.NewListener(s.ListenDsn, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	if err := listener.Listen(s.Channel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Channel, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		// A nil notification means the connection was re-established and changes may have been missed.
+		case <-listener.Notify:
+			notify()
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+// permissionSourceBinder is implemented by sources that need the application, e.g. its databases.
+type permissionSourceBinder interface {
+	bindPermissionSource(f *FluxGo) error
+}
+
+// permissionLoader keeps the source of the permissions of an Http.
+type permissionLoader struct {
+	source   PermissionSource
+	loadedAt atomic.Pointer[time.Time]
+}
+
+// AddPermissionSource loads the permissions of the HTTP routes from source on start,
+// replacing HttpOptions.Permissions, and reloads them while running. A failed reload
+// keeps the previous permissions. Call it after AddHttp and AddDatabase.
+func (f *FluxGo) AddPermissionSource(source PermissionSource, opt PermissionSourceOptions) *FluxGo {
+	if opt.Admin != nil && opt.Admin.Permission == nil {
+		f.dependencies = append(f.dependencies, fx.Error(fmt.Errorf("PermissionsAdminOptions.Permission is required to expose the permissions")))
+		return f
+	}
+	if binder, ok := source.(permissionSourceBinder); ok {
+		if err := binder.bindPermissionSource(f); err != nil {
+			f.dependencies = append(f.dependencies, fx.Error(err))
+			return f
+		}
+	}
+
+	f.addResource(func(lc fx.Lifecycle, http *Http) {
+		http.permissionLoader = &permissionLoader{source: source}
+		if opt.Admin != nil {
+			f.registerPermissionsAdminRoute(http, *opt.Admin)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		lc.Append(fx.Hook{
+			OnStart: func(startCtx context.Context) error {
+				if err := http.ReloadPermissions(startCtx); err != nil {
+					cancel()
+					return err
+				}
+				f.Log("PERMISSIONS", "Loaded")
+
+				go func() {
+					defer close(done)
+					f.watchPermissions(ctx, http, source, opt.ReloadInterval)
+				}()
+				return nil
+			},
+			OnStop: func(stopCtx context.Context) error {
+				cancel()
+				<-done
+				return nil
+			},
+		})
+	})
+
+	return f
+}
+
+func (f *FluxGo) watchPermissions(ctx context.Context, http *Http, source PermissionSource, interval time.Duration) {
+	reload := func() {
+		if err := http.ReloadPermissions(ctx); err != nil {
+			f.LogError("PERMISSIONS", fmt.Sprintf("Reload failed: %v", err))
+		}
+	}
+
+	if watcher, ok := source.(PermissionWatcher); ok {
+		go func() {
+			if err := watcher.WatchPermissions(ctx, reload); err != nil {
+				f.LogError("PERMISSIONS", fmt.Sprintf("Watch failed: %v", err))
+			}
+		}()
+	}
+
+	if interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reload()
+		}
+	}
+}
+
+// ReloadPermissions loads the permissions from the source given to FluxGo.AddPermissionSource.
+func (h *Http) ReloadPermissions(ctx context.Context) error {
+	if h.permissionLoader == nil {
+		return fmt.Errorf("no permission source: call FluxGo.AddPermissionSource")
+	}
+
+	permissions, err := h.permissionLoader.source.LoadPermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	h.SetPermissions(permissions)
+	now := time.Now()
+	h.permissionLoader.loadedAt.Store(&now)
+
+	return nil
+}
+
+// PermissionsSnapshot returns the effective rules of every role.
+func (h *Http) PermissionsSnapshot() PermissionsSnapshot {
+	snapshot := PermissionsSnapshot{Roles: Permissions{}}
+	if permissions := h.permissions.Load(); permissions != nil {
+		snapshot.Roles = *permissions
+	}
+	if h.permissionLoader != nil {
+		if loadedAt := h.permissionLoader.loadedAt.Load(); loadedAt != nil {
+			snapshot.LoadedAt = *loadedAt
+		}
+	}
+
+	return snapshot
+}
+
+func (f *FluxGo) registerPermissionsAdminRoute(http *Http, opt PermissionsAdminOptions) {
+	path := defaultString(opt.Path, defaultPermissionsAdminPath)
+
+	handlers := append([]fiber.Handler{}, opt.Middleware...)
+	handlers = append(handlers, func(c *fiber.Ctx) error {
+		if err := http.checkPermission(c.UserContext(), opt.Permission, nil); err != nil {
+			return http.SendError(c, err)
+		}
+
+		return c.JSON(http.PermissionsSnapshot())
+	})
+
+	http.app.Get(path, handlers...)
+}
@@ -0,0 +1,155 @@
+package fluxgo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type watchedPermissionSource struct {
+	mu          sync.Mutex
+	permissions Permissions
+	notify      chan struct{}
+}
+
+func (s *watchedPermissionSource) LoadPermissions(ctx context.Context) (Permissions, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.permissions, nil
+}
+
+func (s *watchedPermissionSource) WatchPermissions(ctx context.Context, notify func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.notify:
+			notify()
+		}
+	}
+}
+
+func (s *watchedPermissionSource) set(permissions Permissions) {
+	s.mu.Lock()
+	s.permissions = permissions
+	s.mu.Unlock()
+	s.notify <- struct{}{}
+}
+
+func TestPermissionSource(t *testing.T) {
+	t.Run("Should load YAML and JSON permission files", func(t *testing.T) {
+		dir := t.TempDir()
+		yamlFile := filepath.Join(dir, "permissions.yaml")
+		jsonFile := filepath.Join(dir, "permissions.json")
+		assert.NoError(t, os.WriteFile(yamlFile, []byte(`
+viewer:
+  - action: read
+    subject: article
+editor:
+  - inherits: viewer
+  - action: update
+    subject: article
+    conditions:
+      owner_id: ${subject}
+`), 0o600))
+		assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"viewer": [{"action": "read", "subject": "article", "fields": ["title"]}]}`), 0o600))
+
+		permissions, err := FilePermissionSource{Path: yamlFile}.LoadPermissions(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, permissions.Can("editor", "read", "article"))
+		assert.Equal(t, map[string]any{"owner_id": "${subject}"}, permissions["editor"][1].Conditions)
+
+		permissions, err = FilePermissionSource{Path: jsonFile}.LoadPermissions(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"title"}, permissions["viewer"][0].Fields)
+
+		_, err = FilePermissionSource{Path: filepath.Join(dir, "missing.yaml")}.LoadPermissions(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("Should reload on notifications and serve the effective rules", func(t *testing.T) {
+		source := &watchedPermissionSource{
+			permissions: Permissions{"viewer": {{Action: "read", Subject: "article"}}},
+			notify:      make(chan struct{}),
+		}
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{Permissions: &Permissions{"viewer": {}}}, func(h HttpConfigData) {})
+		flux.AddPermissionSource(source, PermissionSourceOptions{Admin: &PermissionsAdminOptions{
+			Permission: &RoutePermission{Action: "read", Subject: "article"},
+			Middleware: []fiber.Handler{func(c *fiber.Ctx) error {
+				c.SetUserContext(context.WithValue(c.UserContext(), RoleContextKey, c.Get("X-Role")))
+				return c.Next()
+			}},
+		}})
+
+		app, http := flux.GetTestApp(t)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		assert.True(t, http.permissions.Load().Can("viewer", "read", "article"))
+
+		source.set(Permissions{
+			"viewer": {{Action: "read", Subject: "article"}},
+			"editor": {{Inherits: "viewer"}, {Action: "update", Subject: "article"}},
+		})
+		assert.Eventually(t, func() bool {
+			return http.permissions.Load().Can("editor", "read", "article")
+		}, time.Second, 10*time.Millisecond)
+
+		status, _ := RunTestRequest(http, "GET", defaultPermissionsAdminPath, nil, nil)
+		assert.Equal(t, 401, status)
+
+		status, body := RunTestRequest(http, "GET", defaultPermissionsAdminPath, nil, &Headers{"X-Role": "viewer"})
+		assert.Equal(t, 200, status)
+		roles := body["roles"].(map[string]interface{})
+		assert.Len(t, roles["editor"], 2)
+		assert.NotEmpty(t, body["loaded_at"])
+	})
+
+	t.Run("Should refuse an unprotected admin endpoint or a channel without a connection", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(h HttpConfigData) {})
+		flux.AddPermissionSource(FilePermissionSource{Path: "permissions.yaml"}, PermissionSourceOptions{Admin: &PermissionsAdminOptions{}})
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "PermissionsAdminOptions.Permission is required")
+
+		flux = New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(h HttpConfigData) {})
+		flux.AddPermissionSource(&DatabasePermissionSource{Channel: "permissions_changed"}, PermissionSourceOptions{})
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "ListenDsn is required")
+	})
+
+	t.Run("Should keep the previous permissions when a reload fails", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "permissions.json")
+		assert.NoError(t, os.WriteFile(file, []byte(`{"viewer": [{"action": "read", "subject": "article"}]}`), 0o600))
+
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(h HttpConfigData) {})
+		flux.AddPermissionSource(FilePermissionSource{Path: file}, PermissionSourceOptions{ReloadInterval: 10 * time.Millisecond})
+
+		app, http := flux.GetTestApp(t)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		assert.NoError(t, os.WriteFile(file, []byte(`{invalid`), 0o600))
+		assert.Error(t, http.ReloadPermissions(context.Background()))
+		assert.True(t, http.permissions.Load().Can("viewer", "read", "article"))
+
+		assert.NoError(t, os.WriteFile(file, []byte(`{"viewer": [{"action": "update", "subject": "article"}]}`), 0o600))
+		assert.Eventually(t, func() bool {
+			return http.permissions.Load().Can("viewer", "update", "article")
+		}, time.Second, 10*time.Millisecond)
+	})
+}
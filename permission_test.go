@@ -0,0 +1,162 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type permissionTestArticle struct {
+	ID       int    `json:"id" params:"id"`
+	OwnerID  string `json:"owner_id"`
+	Status   string `json:"status"`
+	Priority int    `json:"priority"`
+}
+
+type permissionTestHandler struct{}
+
+func (h *permissionTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+}
+
+func TestPermissions(t *testing.T) {
+	permissions := Permissions{
+		"viewer": {
+			{Action: "read", Subject: "article"},
+			{Action: "read", Subject: "article", Fields: []string{"internal_notes"}, Inverted: true},
+		},
+		"editor": {
+			{Inherits: "viewer"},
+			{Action: "update", Subject: "article", Conditions: map[string]any{"owner_id": "${subject}"}},
+			{Action: "update", Subject: "article", Conditions: map[string]any{"status": "published"}, Inverted: true, Reason: "Published articles are frozen"},
+		},
+		"admin": {
+			{Inherits: "editor"},
+			{Action: "manage", Subject: "all"},
+		},
+		"reviewer": {
+			{Action: "approve", Subject: "article", Conditions: map[string]any{"priority": map[string]any{"$gte": 5}, "status": map[string]any{"$in": []string{"draft", "review"}}}},
+		},
+		"loop":   {{Inherits: "loop"}, {Action: "read", Subject: "article"}},
+		"author": {{Action: "read", Subject: "draft", Conditions: map[string]any{"owner_id": "${subject}"}}},
+	}
+	ctx := context.WithValue(context.Background(), SubjectContextKey, "user-1")
+
+	t.Run("Should include the rules of inherited roles", func(t *testing.T) {
+		assert.True(t, permissions.Can("editor", "read", "article"))
+		assert.True(t, permissions.Can("admin", "delete", "comment"))
+		assert.False(t, permissions.Can("viewer", "update", "article"))
+		assert.True(t, permissions.Can("loop", "read", "article"))
+	})
+
+	t.Run("Should match conditions against the resource", func(t *testing.T) {
+		own := permissionTestArticle{OwnerID: "user-1", Status: "draft"}
+		other := permissionTestArticle{OwnerID: "user-2", Status: "draft"}
+		published := permissionTestArticle{OwnerID: "user-1", Status: "published"}
+
+		assert.True(t, permissions.Check(ctx, "editor", PermissionCheck{Action: "update", Subject: "article", Resource: own}))
+		assert.False(t, permissions.Check(ctx, "editor", PermissionCheck{Action: "update", Subject: "article", Resource: other}))
+		assert.False(t, permissions.Check(ctx, "editor", PermissionCheck{Action: "update", Subject: "article", Resource: &published}))
+		assert.False(t, permissions.Check(context.Background(), "editor", PermissionCheck{Action: "update", Subject: "article", Resource: permissionTestArticle{}}))
+		assert.True(t, permissions.Check(ctx, "editor", PermissionCheck{Action: "update", Subject: "article"}))
+	})
+
+	t.Run("Should match numeric ids against the string subject", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), SubjectContextKey, "42")
+		type account struct {
+			OwnerID int `json:"owner_id"`
+		}
+
+		assert.True(t, permissions.Check(ctx, "editor", PermissionCheck{Action: "update", Subject: "article", Resource: account{OwnerID: 42}}))
+		assert.False(t, permissions.Check(ctx, "editor", PermissionCheck{Action: "update", Subject: "article", Resource: account{OwnerID: 7}}))
+	})
+
+	t.Run("Should apply operators", func(t *testing.T) {
+		check := func(article permissionTestArticle) bool {
+			return permissions.Check(ctx, "reviewer", PermissionCheck{Action: "approve", Subject: "article", Resource: article})
+		}
+
+		assert.True(t, check(permissionTestArticle{Priority: 5, Status: "review"}))
+		assert.False(t, check(permissionTestArticle{Priority: 4, Status: "review"}))
+		assert.False(t, check(permissionTestArticle{Priority: 9, Status: "published"}))
+	})
+
+	t.Run("Should deny fields of inverted rules", func(t *testing.T) {
+		assert.True(t, permissions.Check(ctx, "viewer", PermissionCheck{Action: "read", Subject: "article", Field: "title"}))
+		assert.False(t, permissions.Check(ctx, "viewer", PermissionCheck{Action: "read", Subject: "article", Field: "internal_notes"}))
+	})
+
+	t.Run("Should serialize the resolved rules with interpolated conditions", func(t *testing.T) {
+		http := &Http{}
+		http.SetPermissions(permissions)
+
+		rules := http.GetPermissions(context.WithValue(ctx, RoleContextKey, "editor"))
+		content, _ := json.Marshal(rules)
+
+		assert.Len(t, rules, 4)
+		assert.Contains(t, string(content), `{"action":"update","subject":"article","conditions":{"owner_id":"user-1"}}`)
+		assert.Contains(t, string(content), `"inverted":true,"reason":"Published articles are frozen"`)
+		assert.NotContains(t, string(content), "inherits")
+	})
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Permissions: &permissions}, func(h HttpConfigData) {
+		h.CreateRouter("/api", WithMiddleware(func(c *fiber.Ctx) error {
+			ctx := context.WithValue(c.UserContext(), RoleContextKey, c.Get("X-Role"))
+			c.SetUserContext(context.WithValue(ctx, SubjectContextKey, c.Get("X-User")))
+			return c.Next()
+		}))
+	})
+	cache := &memoryTestCache{values: map[string]string{}}
+	flux.AddModule(Module("test").
+		AddHandler(func() *permissionTestHandler { return &permissionTestHandler{} }).
+		Route(PUT[permissionTestHandler]("/api", "/articles/:id", RouteIncome{
+			Entity:   permissionTestArticle{},
+			FromBody: true,
+			Permission: &RoutePermission{Action: "update", Subject: "article", Resource: func(c *fiber.Ctx, income interface{}) (any, *GlobalError) {
+				article := income.(*permissionTestArticle)
+				return permissionTestArticle{ID: article.ID, OwnerID: "user-1", Status: article.Status}, nil
+			}},
+		})).
+		Route(GET[permissionTestHandler]("/api", "/drafts/:id", RouteIncome{
+			Entity:    permissionTestArticle{},
+			FromParam: true,
+			Cache:     cache,
+			CacheTTL:  time.Minute,
+			Permission: &RoutePermission{Action: "read", Subject: "draft", Resource: func(c *fiber.Ctx, income interface{}) (any, *GlobalError) {
+				return permissionTestArticle{ID: income.(*permissionTestArticle).ID, OwnerID: "user-1"}, nil
+			}},
+		})))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should check route permissions against the loaded resource", func(t *testing.T) {
+		body := map[string]any{"status": "draft"}
+
+		status, _ := RunTestRequestRaw(http, "PUT", "/api/articles/1", body, &Headers{"X-Role": "editor", "X-User": "user-1"})
+		assert.Equal(t, 200, status)
+
+		status, _ = RunTestRequestRaw(http, "PUT", "/api/articles/1", body, &Headers{"X-Role": "editor", "X-User": "user-2"})
+		assert.Equal(t, 403, status)
+
+		status, _ = RunTestRequestRaw(http, "PUT", "/api/articles/1", body, &Headers{"X-Role": "viewer", "X-User": "user-1"})
+		assert.Equal(t, 403, status)
+	})
+
+	t.Run("Should check conditional rules before serving cached responses", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "GET", "/api/drafts/1", nil, &Headers{"X-Role": "author", "X-User": "user-1"})
+		assert.Equal(t, 200, status)
+		assert.Eventually(t, func() bool { return cache.Get(context.Background(), "test:endpoint:/api/drafts/1") != nil }, time.Second, 10*time.Millisecond)
+
+		status, _ = RunTestRequestRaw(http, "GET", "/api/drafts/1", nil, &Headers{"X-Role": "author", "X-User": "user-2"})
+		assert.Equal(t, 403, status)
+
+		status, _ = RunTestRequestRaw(http, "GET", "/api/drafts/1", nil, &Headers{"X-Role": "author", "X-User": "user-1"})
+		assert.Equal(t, 200, status)
+	})
+}
@@ -0,0 +1,180 @@
+package fluxgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"go.uber.org/fx"
+)
+
+// Plugin is a subsystem added with FluxGo.Use. Provide returns constructors registered
+// like AddDependency; the optional interfaces below add lifecycle, health and config.
+type Plugin interface {
+	Name() string
+	Provide() []interface{}
+}
+
+// PluginInitializer receives the application before its constructors are registered,
+// e.g. to keep it for FluxGo.Log or FluxGo.Go.
+type PluginInitializer interface {
+	Init(f *FluxGo) error
+}
+
+// PluginStarter starts with the resources (Database, Redis, ...), before modules and ingress.
+type PluginStarter interface {
+	Start(ctx context.Context) error
+}
+
+// PluginStopper stops with the resources, after ingress stopped and background tasks drained.
+type PluginStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// PluginHealthChecker is registered as a health check named after the plugin.
+type PluginHealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// PluginConfigurer exposes the plugin options; their JSON schema is listed by FluxGo.Plugins.
+type PluginConfigurer interface {
+	Options() any
+}
+
+type PluginInfo struct {
+	Name   string             `json:"name"`
+	Schema *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+// Use adds a plugin. Modules can depend on it with Requires(Dependency(plugin.Name())).
+// Plugins must be added before the first GetFxConfig call.
+func (f *FluxGo) Use(plugin Plugin) *FluxGo {
+	if f.pluginAddedLate(plugin) {
+		return f
+	}
+
+	for _, existing := range f.plugins {
+		if existing.Name() == plugin.Name() {
+			f.dependencies = append(f.dependencies, fx.Error(fmt.Errorf("plugin %q already added", plugin.Name())))
+			return f
+		}
+	}
+
+	f.markProvided(Dependency(plugin.Name()))
+	f.plugins = append(f.plugins, plugin)
+
+	return f
+}
+
+// ReplacePlugin swaps the plugin with the same name, typically for a fake in tests.
+// The replacement must provide the same types as the original.
+func (f *FluxGo) ReplacePlugin(plugin Plugin) *FluxGo {
+	if f.pluginAddedLate(plugin) {
+		return f
+	}
+
+	for i, existing := range f.plugins {
+		if existing.Name() == plugin.Name() {
+			f.plugins[i] = plugin
+			return f
+		}
+	}
+
+	f.dependencies = append(f.dependencies, fx.Error(fmt.Errorf("no plugin named %q to replace: call FluxGo.Use first", plugin.Name())))
+
+	return f
+}
+
+// Plugins lists the plugins in use with the JSON schema of their options.
+func (f *FluxGo) Plugins() []PluginInfo {
+	infos := make([]PluginInfo, 0, len(f.plugins))
+	for _, plugin := range f.plugins {
+		info := PluginInfo{Name: plugin.Name()}
+		if configurer, ok := plugin.(PluginConfigurer); ok && configurer.Options() != nil {
+			info.Schema = jsonschema.Reflect(configurer.Options())
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// pluginOptions registers every plugin in the resources tier. Plugins are initialized
+// once, on the first call, so building the fx config again reuses the same options.
+func (f *FluxGo) pluginOptions() []fx.Option {
+	f.pluginsOnce.Do(func() {
+		f.pluginsInit = true
+		f.pluginOpts = f.initPlugins()
+	})
+
+	return f.pluginOpts
+}
+
+// pluginAddedLate fails the application when plugin comes after the plugins were initialized.
+func (f *FluxGo) pluginAddedLate(plugin Plugin) bool {
+	if !f.pluginsInit {
+		return false
+	}
+
+	err := fmt.Errorf("plugin %q added after GetFxConfig: call FluxGo.Use and ReplacePlugin before building the application", plugin.Name())
+	f.LogError("PLUGIN", err.Error())
+	f.dependencies = append(f.dependencies, fx.Error(err))
+
+	return true
+}
+
+func (f *FluxGo) initPlugins() []fx.Option {
+	options := []fx.Option{}
+
+	for _, plugin := range f.plugins {
+		p := plugin
+		key := strings.ToUpper(p.Name())
+
+		if initializer, ok := p.(PluginInitializer); ok {
+			if err := initializer.Init(f); err != nil {
+				options = append(options, fx.Error(fmt.Errorf("plugin %s: %w", p.Name(), err)))
+				continue
+			}
+		}
+
+		if constructors := p.Provide(); len(constructors) > 0 {
+			options = append(options, fx.Provide(constructors...))
+		}
+
+		if checker, ok := p.(PluginHealthChecker); ok {
+			f.health.Register(p.Name(), checker.HealthCheck)
+		}
+
+		starter, hasStart := p.(PluginStarter)
+		stopper, hasStop := p.(PluginStopper)
+		if !hasStart && !hasStop {
+			continue
+		}
+
+		options = append(options, fx.Invoke(func(lc fx.Lifecycle) {
+			hook := fx.Hook{}
+			if hasStart {
+				hook.OnStart = func(ctx context.Context) error {
+					if err := starter.Start(ctx); err != nil {
+						return fmt.Errorf("plugin %s: %w", p.Name(), err)
+					}
+					f.Log(key, "Started")
+					return nil
+				}
+			}
+			if hasStop {
+				hook.OnStop = func(ctx context.Context) error {
+					if err := stopper.Stop(ctx); err != nil {
+						return fmt.Errorf("plugin %s: %w", p.Name(), err)
+					}
+					f.Log(key, "Stopped")
+					return nil
+				}
+			}
+			lc.Append(hook)
+		}))
+	}
+
+	return options
+}
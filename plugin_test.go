@@ -0,0 +1,126 @@
+package fluxgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type pluginTestClient struct {
+	Bucket string
+}
+
+type pluginTestOptions struct {
+	Bucket string `json:"bucket"`
+}
+
+type pluginTestStorage struct {
+	opt     pluginTestOptions
+	calls   []string
+	flux    *FluxGo
+	inits   int
+	healthy error
+}
+
+func (p *pluginTestStorage) Name() string { return "storage" }
+func (p *pluginTestStorage) Provide() []interface{} {
+	return []interface{}{func() *pluginTestClient { return &pluginTestClient{Bucket: p.opt.Bucket} }}
+}
+func (p *pluginTestStorage) Init(f *FluxGo) error {
+	p.flux = f
+	p.inits++
+	return nil
+}
+func (p *pluginTestStorage) Start(ctx context.Context) error {
+	p.calls = append(p.calls, "start")
+	return nil
+}
+func (p *pluginTestStorage) Stop(ctx context.Context) error {
+	p.calls = append(p.calls, "stop")
+	return nil
+}
+func (p *pluginTestStorage) HealthCheck(ctx context.Context) error { return p.healthy }
+func (p *pluginTestStorage) Options() any                          { return p.opt }
+
+func TestPlugin(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should provide, start, stop and health check a plugin", func(t *testing.T) {
+		plugin := &pluginTestStorage{opt: pluginTestOptions{Bucket: "files"}, healthy: assert.AnError}
+
+		var client *pluginTestClient
+		flux := New(FluxGoConfig{Name: "Test"}).Use(plugin)
+		flux.AddInvoke(func(c *pluginTestClient) { client = c })
+
+		app := fx.New(flux.GetFxConfig()...)
+		assert.NoError(t, app.Start(ctx))
+
+		assert.Equal(t, "files", client.Bucket)
+		assert.Same(t, flux, plugin.flux)
+		assert.Equal(t, []string{"storage"}, flux.health.Check(ctx).FailedChecks())
+
+		assert.NoError(t, app.Stop(ctx))
+		assert.Equal(t, []string{"start", "stop"}, plugin.calls)
+	})
+
+	t.Run("Should initialize plugins once across fx configs", func(t *testing.T) {
+		plugin := &pluginTestStorage{}
+		flux := New(FluxGoConfig{Name: "Test"}).Use(plugin)
+
+		flux.GetFxConfig()
+		assert.NoError(t, fx.New(flux.GetFxConfig()...).Err())
+		assert.Equal(t, 1, plugin.inits)
+	})
+
+	t.Run("Should reject plugins added after the fx config is built", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.GetFxConfig()
+
+		flux.Use(&pluginTestStorage{})
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), `plugin "storage" added after GetFxConfig`)
+	})
+
+	t.Run("Should replace a plugin for tests", func(t *testing.T) {
+		fake := &pluginTestStorage{opt: pluginTestOptions{Bucket: "fake"}}
+
+		var client *pluginTestClient
+		flux := New(FluxGoConfig{Name: "Test"}).
+			Use(&pluginTestStorage{opt: pluginTestOptions{Bucket: "files"}}).
+			ReplacePlugin(fake)
+		flux.AddInvoke(func(c *pluginTestClient) { client = c })
+
+		assert.NoError(t, fx.New(flux.GetFxConfig()...).Err())
+		assert.Equal(t, "fake", client.Bucket)
+	})
+
+	t.Run("Should reject duplicated plugins", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"}).
+			Use(&pluginTestStorage{}).
+			Use(&pluginTestStorage{})
+
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), `plugin "storage" already added`)
+	})
+
+	t.Run("Should satisfy module requirements", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddModule(Module("files", Requires(Dependency("storage"))))
+
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "storage (call FluxGo.Use with the plugin)")
+
+		flux = New(FluxGoConfig{Name: "Test"}).Use(&pluginTestStorage{})
+		flux.AddModule(Module("files", Requires(Dependency("storage"))))
+		assert.NoError(t, fx.New(flux.GetFxConfig()...).Err())
+	})
+
+	t.Run("Should list plugins with their options schema", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"}).Use(&pluginTestStorage{})
+
+		plugins := flux.Plugins()
+
+		assert.Len(t, plugins, 1)
+		assert.Equal(t, "storage", plugins[0].Name)
+		assert.NotNil(t, plugins[0].Schema)
+	})
+}
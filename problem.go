@@ -0,0 +1,95 @@
+package fluxgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorFormatter renders a GlobalError returned by handlers, validation, parsing,
+// permission checks or recovered panics.
+type ErrorFormatter func(c *fiber.Ctx, err *GlobalError) error
+
+// DefaultErrorFormatter writes the GlobalError as JSON with its status.
+func DefaultErrorFormatter(c *fiber.Ctx, err *GlobalError) error {
+	return c.Status(err.Status).JSON(err)
+}
+
+type ProblemDetailsOptions struct {
+	// TypeBaseURL prefixes the error code to build the problem type, e.g.
+	// "https://api.example.com/problems/" + "not_found". Default: "about:blank".
+	TypeBaseURL string
+}
+
+// ProblemDetails is the RFC 7807 application/problem+json body.
+type ProblemDetails struct {
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Status      int    `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	Instance    string `json:"instance,omitempty"`
+	Code        string `json:"code,omitempty"`
+	TraceId     string `json:"trace_id,omitempty"`
+	UserMessage string `json:"user_message,omitempty"`
+	Errors      any    `json:"errors,omitempty"`
+}
+
+// ProblemDetailsFormatter renders errors as RFC 7807 problem+json including the
+// request path as instance, the trace ID and validation details.
+func ProblemDetailsFormatter(opt ProblemDetailsOptions) ErrorFormatter {
+	return func(c *fiber.Ctx, err *GlobalError) error {
+		status := err.Status
+		if status == 0 {
+			status = fiber.StatusInternalServerError
+		}
+
+		problem := ProblemDetails{
+			Type:        "about:blank",
+			Title:       http.StatusText(status),
+			Status:      status,
+			Detail:      err.Message,
+			Instance:    c.OriginalURL(),
+			Code:        err.Code,
+			UserMessage: err.UserMessage,
+			Errors:      err.Errors,
+		}
+		if opt.TypeBaseURL != "" && err.Code != "" {
+			problem.Type = strings.TrimRight(opt.TypeBaseURL, "/") + "/" + err.Code
+		}
+		if span := trace.SpanFromContext(c.UserContext()).SpanContext(); span.HasTraceID() {
+			problem.TraceId = span.TraceID().String()
+		}
+
+		return c.Status(status).JSON(problem, "application/problem+json")
+	}
+}
+
+// SendError renders err with the configured ErrorFormatter.
+func (h *Http) SendError(c *fiber.Ctx, err *GlobalError) error {
+	if h.errorFormatter != nil {
+		return h.errorFormatter(c, err)
+	}
+
+	return DefaultErrorFormatter(c, err)
+}
+
+// fiberErrorHandler routes errors raised outside HttpRoute (unknown routes, middleware) through SendError.
+// Other errors than *fiber.Error are logged and answered with a generic 500.
+func (h *Http) fiberErrorHandler(f *FluxGo, c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return h.SendError(c, &GlobalError{
+			Message: fiberErr.Message,
+			Code:    "error." + strings.ReplaceAll(strings.ToLower(http.StatusText(fiberErr.Code)), " ", "_"),
+			Status:  fiberErr.Code,
+		})
+	}
+
+	f.LogError("HTTP", fmt.Sprintf("Unhandled error on %s %s: %v", c.Method(), c.Path(), err))
+
+	return h.SendError(c, &GlobalError{Message: "Internal server error", Code: "error.internal", Status: fiber.StatusInternalServerError})
+}
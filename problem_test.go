@@ -0,0 +1,110 @@
+package fluxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type problemTestHandler struct{}
+
+func (h *problemTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	switch c.Query("mode") {
+	case "panic":
+		panic("boom")
+	case "missing":
+		return nil, ErrorNotFound("User not found")
+	}
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+}
+
+type problemTestEntity struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func problemTestApp(t *testing.T, formatter ErrorFormatter) *Http {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{ErrorFormatter: formatter, Permissions: &Permissions{}}, func(HttpConfigData) {})
+	flux.AddModule(Module("test").
+		AddHandler(func() *problemTestHandler { return &problemTestHandler{} }).
+		Route(
+			GET[problemTestHandler]("", "/users", RouteIncome{}),
+			POST[problemTestHandler]("", "/users", RouteIncome{Entity: problemTestEntity{}, FromBody: true, Validate: true}),
+			GET[problemTestHandler]("", "/admin", RouteIncome{Permission: &RoutePermission{Action: "read", Subject: "admin"}}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	return http
+}
+
+func TestErrorFormatter(t *testing.T) {
+	problems := problemTestApp(t, ProblemDetailsFormatter(ProblemDetailsOptions{TypeBaseURL: "https://errors.example.com/"}))
+
+	parse := func(body []byte) ProblemDetails {
+		var problem ProblemDetails
+		assert.NoError(t, json.Unmarshal(body, &problem))
+		return problem
+	}
+
+	t.Run("Should render handler errors as problem+json", func(t *testing.T) {
+		status, body := RunTestRequestRaw(problems, "GET", "/users?mode=missing", nil, nil)
+		problem := parse(body)
+
+		assert.Equal(t, 404, status)
+		assert.Equal(t, "https://errors.example.com/not_found", problem.Type)
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, 404, problem.Status)
+		assert.Equal(t, "User not found", problem.Detail)
+		assert.Equal(t, "/users?mode=missing", problem.Instance)
+	})
+
+	t.Run("Should include validation details", func(t *testing.T) {
+		status, body := RunTestRequestRaw(problems, "POST", "/users", map[string]string{}, nil)
+		problem := parse(body)
+
+		assert.Equal(t, 400, status)
+		assert.Equal(t, "error.validation", problem.Code)
+		assert.NotEmpty(t, problem.Errors)
+	})
+
+	t.Run("Should render permission errors and recovered panics", func(t *testing.T) {
+		status, body := RunTestRequestRaw(problems, "GET", "/admin", nil, nil)
+		assert.Equal(t, 401, status)
+		assert.Equal(t, "Unauthorized", parse(body).Detail)
+
+		status, body = RunTestRequestRaw(problems, "GET", "/users?mode=panic", nil, nil)
+		assert.Equal(t, 500, status)
+		assert.Equal(t, "internal_error", parse(body).Code)
+	})
+
+	t.Run("Should render unknown routes", func(t *testing.T) {
+		status, body := RunTestRequestRaw(problems, "GET", "/missing", nil, nil)
+
+		assert.Equal(t, 404, status)
+		assert.Equal(t, "error.not_found", parse(body).Code)
+	})
+
+	t.Run("Should hide errors raised outside routes", func(t *testing.T) {
+		problems.GetApp().Get("/broken", func(c *fiber.Ctx) error { return errors.New("dial tcp 10.0.0.1:5432: password authentication failed") })
+
+		status, body := RunTestRequestRaw(problems, "GET", "/broken", nil, nil)
+		assert.Equal(t, 500, status)
+		assert.Equal(t, "error.internal", parse(body).Code)
+		assert.Equal(t, "Internal server error", parse(body).Detail)
+	})
+
+	t.Run("Should keep the GlobalError body by default", func(t *testing.T) {
+		status, body := RunTestRequestRaw(problemTestApp(t, nil), "GET", "/users?mode=missing", nil, nil)
+
+		var gErr GlobalError
+		assert.NoError(t, json.Unmarshal(body, &gErr))
+		assert.Equal(t, 404, status)
+		assert.Equal(t, "not_found", gErr.Code)
+		assert.Equal(t, "User not found", gErr.Message)
+	})
+}
@@ -23,6 +23,8 @@ type Prometheus struct {
 }
 
 func (f *FluxGo) AddPrometheus() *Prometheus {
+	f.markProvided(DependencyPrometheus)
+
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(collectors.NewGoCollector())
 
@@ -0,0 +1,240 @@
+package fluxgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimit limits how many requests a client can make to a route in a sliding window.
+// Window, and Limit unless LimitFunc is set, must be positive.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+	// LimitFunc overrides Limit on every request, e.g. to read it from a reloadable Config.
+	LimitFunc func() int
+	// Key identifies the client. Default: RateLimitByIP
+	Key RateLimitKeyFunc
+	// Store keeps the request counters. Default: Redis when added, otherwise in memory.
+	Store RateLimitStore
+}
+
+// RateLimitKeyFunc returns the identifier of the client making the request.
+type RateLimitKeyFunc func(c *fiber.Ctx) string
+
+func RateLimitByIP() RateLimitKeyFunc {
+	return func(c *fiber.Ctx) string {
+		return c.IP()
+	}
+}
+
+// RateLimitByRole shares the limit among every request with the same role, falling back to the IP.
+func RateLimitByRole() RateLimitKeyFunc {
+	return func(c *fiber.Ctx) string {
+		if role, _ := c.UserContext().Value(RoleContextKey).(string); role != "" {
+			return "role:" + role
+		}
+		return c.IP()
+	}
+}
+
+// RateLimitByHeader keys the limit by a header such as an API key, falling back to the IP.
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(c *fiber.Ctx) string {
+		if value := c.Get(name); value != "" {
+			return "header:" + value
+		}
+		return c.IP()
+	}
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the oldest request in the window expires.
+	Reset time.Duration
+}
+
+// RateLimitStore counts requests in a sliding window.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error)
+}
+
+func (r RateLimit) validate() error {
+	if r.Window <= 0 {
+		return fmt.Errorf("rate limit window must be positive, got %s", r.Window)
+	}
+	if r.LimitFunc == nil && r.Limit <= 0 {
+		return fmt.Errorf("rate limit must be positive, got %d", r.Limit)
+	}
+
+	return nil
+}
+
+func (r RateLimit) limit() int {
+	if r.LimitFunc != nil {
+		return r.LimitFunc()
+	}
+	return r.Limit
+}
+
+// check applies the rate limit to the request, setting the RateLimit-* headers.
+// A store failure lets the request through.
+func (r *RateLimit) check(c *fiber.Ctx, f *FluxGo, store RateLimitStore, route string) *GlobalError {
+	keyFn := r.Key
+	if keyFn == nil {
+		keyFn = RateLimitByIP()
+	}
+
+	limit := r.limit()
+	key := fmt.Sprintf("%s:ratelimit:%s:%s", f.GetCleanName(), route, keyFn(c))
+
+	result, err := store.Allow(c.UserContext(), key, limit, r.Window)
+	if err != nil {
+		f.LogError("HTTP", fmt.Sprintf("Rate limit store failed: %v", err))
+		return nil
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+	c.Set("RateLimit-Limit", strconv.Itoa(limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("RateLimit-Reset", reset)
+
+	if result.Allowed {
+		return nil
+	}
+
+	c.Set(fiber.HeaderRetryAfter, reset)
+
+	return &GlobalError{
+		Message: "Too many requests",
+		Code:    "error.rate_limited",
+		Status:  fiber.StatusTooManyRequests,
+		Success: false,
+	}
+}
+
+// MemoryRateLimitStore keeps a sliding window log per key in the process.
+// Limits are per instance; use RedisRateLimitStore when running several pods.
+type MemoryRateLimitStore struct {
+	mu    sync.Mutex
+	hits  map[string]*rateLimitLog
+	calls int
+}
+
+// rateLimitLog keeps the window of its key, since routes with different windows share the store.
+type rateLimitLog struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{hits: map[string]*rateLimitLog{}}
+}
+
+func (s *MemoryRateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry := s.hits[key]
+	if entry == nil {
+		entry = &rateLimitLog{}
+		s.hits[key] = entry
+	}
+	entry.window = window
+	hits := pruneHits(entry.hits, now.Add(-window))
+
+	result := RateLimitResult{}
+	if len(hits) < limit {
+		hits = append(hits, now)
+		result.Allowed = true
+	}
+	result.Remaining = max(limit-len(hits), 0)
+	if len(hits) > 0 {
+		result.Reset = hits[0].Add(window).Sub(now)
+	}
+	entry.hits = hits
+
+	s.calls++
+	if s.calls%1000 == 0 {
+		s.sweep(now)
+	}
+
+	return result, nil
+}
+
+// sweep drops the keys without requests in their last window.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, entry := range s.hits {
+		if len(entry.hits) == 0 || entry.hits[len(entry.hits)-1].Before(now.Add(-entry.window)) {
+			delete(s.hits, key)
+		}
+	}
+}
+
+func pruneHits(hits []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(since) {
+		i++
+	}
+
+	return hits[i:]
+}
+
+// RedisRateLimitStore keeps a sliding window log per key in a Redis sorted set,
+// so every instance shares the same limits.
+type RedisRateLimitStore struct {
+	redis *Redis
+}
+
+func NewRedisRateLimitStore(redis *Redis) *RedisRateLimitStore {
+	return &RedisRateLimitStore{redis: redis}
+}
+
+var rateLimitScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 10) + ":" + strconv.Itoa(GetRandomNumber(math.MaxInt32))
+
+	values, err := rateLimitScript.Run(ctx, s.redis.client, []string{key}, now.UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	return RateLimitResult{
+		Allowed:   values[0] == 1,
+		Remaining: int(max(values[1], 0)),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
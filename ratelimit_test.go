@@ -0,0 +1,118 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type rateLimitTestHandler struct{}
+
+func (h *rateLimitTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("Should allow requests within a sliding window", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+		ctx := context.Background()
+
+		first, _ := store.Allow(ctx, "key", 2, 50*time.Millisecond)
+		second, _ := store.Allow(ctx, "key", 2, 50*time.Millisecond)
+		third, _ := store.Allow(ctx, "key", 2, 50*time.Millisecond)
+		other, _ := store.Allow(ctx, "other", 2, 50*time.Millisecond)
+
+		assert.True(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+		assert.True(t, second.Allowed)
+		assert.False(t, third.Allowed)
+		assert.Equal(t, 0, third.Remaining)
+		assert.True(t, other.Allowed)
+
+		time.Sleep(60 * time.Millisecond)
+
+		fourth, _ := store.Allow(ctx, "key", 2, 50*time.Millisecond)
+		assert.True(t, fourth.Allowed)
+	})
+
+	t.Run("Should sweep each key by its own window", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+		ctx := context.Background()
+
+		_, _ = store.Allow(ctx, "hourly", 1, time.Hour)
+		time.Sleep(5 * time.Millisecond)
+		for range 999 {
+			_, _ = store.Allow(ctx, "fast", 1000, time.Millisecond)
+		}
+
+		hourly, _ := store.Allow(ctx, "hourly", 1, time.Hour)
+		assert.False(t, hourly.Allowed)
+	})
+
+	t.Run("Should refuse routes without a positive limit or window", func(t *testing.T) {
+		for _, limit := range []RateLimit{{Limit: 10}, {Window: time.Minute}} {
+			flux := New(FluxGoConfig{Name: "Test"})
+			flux.AddApm()
+			flux.AddHttp(HttpOptions{}, func(h HttpConfigData) { h.CreateRouter("/api") })
+			flux.AddModule(Module("test").
+				AddHandler(func() *rateLimitTestHandler { return &rateLimitTestHandler{} }).
+				Route(GET[rateLimitTestHandler]("/api", "/limited", RouteIncome{RateLimit: &limit})))
+
+			assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "GET /api/limited: rate limit")
+		}
+	})
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Swagger: &SwaggerOptions{}}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *rateLimitTestHandler { return &rateLimitTestHandler{} }).
+		Route(
+			GET[rateLimitTestHandler]("/api", "/limited", RouteIncome{RateLimit: &RateLimit{Limit: 2, Window: time.Minute, Key: RateLimitByHeader("X-Api-Key")}}),
+			GET[rateLimitTestHandler]("/api", "/open", RouteIncome{}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should reply 429 with rate limit headers once the limit is reached", func(t *testing.T) {
+		headers := &Headers{"X-Api-Key": "client-a"}
+
+		for i := 0; i < 2; i++ {
+			status, _ := RunTestRequestRaw(http, "GET", "/api/limited", nil, headers)
+			assert.Equal(t, 200, status)
+		}
+
+		req := httptest.NewRequest("GET", "/api/limited", nil)
+		req.Header.Set("X-Api-Key", "client-a")
+		res, err := http.GetApp().Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 429, res.StatusCode)
+		assert.Equal(t, "2", res.Header.Get("RateLimit-Limit"))
+		assert.Equal(t, "0", res.Header.Get("RateLimit-Remaining"))
+		assert.NotEmpty(t, res.Header.Get("Retry-After"))
+
+		status, _ := RunTestRequestRaw(http, "GET", "/api/limited", nil, &Headers{"X-Api-Key": "client-b"})
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should not limit routes without RateLimit", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			status, _ := RunTestRequestRaw(http, "GET", "/api/open", nil, nil)
+			assert.Equal(t, 200, status)
+		}
+	})
+
+	t.Run("Should document the 429 response", func(t *testing.T) {
+		spec, err := json.Marshal(http.OpenAPISpec("/api"))
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(spec), "limited to 2 requests per 1m0s")
+		assert.Contains(t, string(spec), "RateLimit-Remaining")
+	})
+}
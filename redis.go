@@ -3,6 +3,7 @@ package fluxgo
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -16,13 +17,32 @@ type Redis struct {
 }
 type RedisOptions struct {
 	redis.Options
+	// PasswordSecret is the key of the password in FluxGo.AddSecrets. New connections
+	// always use the latest value, so rotations apply without restarting.
+	PasswordSecret string
 }
 
 func (f *FluxGo) AddRedis(opt RedisOptions) *FluxGo {
-	f.AddDependency(func(apm *Apm) *Redis {
-		return &Redis{client: redis.NewClient(&opt.Options), apm: apm}
+	f.markProvided(DependencyRedis)
+
+	f.AddDependency(func(apm *Apm) (*Redis, error) {
+		if opt.PasswordSecret != "" {
+			if f.secrets == nil {
+				return nil, fmt.Errorf("redis PasswordSecret requires FluxGo.AddSecrets")
+			}
+
+			secrets, key, username := f.secrets, opt.PasswordSecret, opt.Username
+			opt.CredentialsProviderContext = func(ctx context.Context) (string, string, error) {
+				password, err := secrets.Get(ctx, key)
+				return username, password, err
+			}
+		}
+
+		return &Redis{client: redis.NewClient(&opt.Options), apm: apm}, nil
 	})
-	f.AddInvoke(func(lc fx.Lifecycle, redis *Redis) error {
+	f.addResource(func(lc fx.Lifecycle, redis *Redis) error {
+		f.AddHealthCheck("redis", redis.connect)
+
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				if err := redis.connect(ctx); err != nil {
@@ -106,6 +126,43 @@ func (r *Redis) StoreString(ctx context.Context, key string, value string, ttl t
 
 	return nil
 }
+
+// StoreIfAbsent stores value as JSON only when key does not exist, reporting whether it did.
+func (r *Redis) StoreIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	ctx, span := r.apm.StartSpan(ctx, "redis/storeIfAbsent", SetAttributes(attribute.String("key", key)))
+	defer span.End()
+
+	contentString, err := json.Marshal(value)
+	if err != nil {
+		span.SetError(err)
+		return false, err
+	}
+
+	stored, err := r.client.SetNX(ctx, key, contentString, ttl).Result()
+	if err != nil {
+		span.SetError(err)
+		return false, err
+	}
+
+	return stored, nil
+}
+
+// Delete removes the keys as written, without matching them as patterns.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	ctx, span := r.apm.StartSpan(ctx, "redis/delete", SetAttributes(attribute.StringSlice("key", keys)))
+	defer span.End()
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	return nil
+}
 func (r *Redis) Invalidate(ctx context.Context, keys []string) error {
 	ctx, span := r.apm.StartSpan(ctx, "redis/invalidate", SetAttributes(attribute.StringSlice("key", keys)))
 	defer span.End()
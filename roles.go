@@ -0,0 +1,58 @@
+package fluxgo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Role selects which ingress subsystems a process activates.
+// When no role is configured every subsystem is active.
+type Role string
+
+const (
+	RoleHttp  Role = "http"
+	RoleGrpc  Role = "grpc"
+	RoleKafka Role = "kafka"
+	RoleCron  Role = "cron"
+)
+
+var knownRoles = []Role{RoleHttp, RoleGrpc, RoleKafka, RoleCron}
+
+// HasRole reports whether the role is enabled for this process.
+// Dependencies are always provided; roles only gate route registration and lifecycle hooks.
+func (f *FluxGo) HasRole(role Role) bool {
+	if role == "" || len(f.Roles) == 0 {
+		return true
+	}
+
+	return slices.Contains(f.Roles, role)
+}
+
+// isServing reports whether the ingress subsystem for role must start listening.
+// Commands build the same application but never serve traffic.
+func (f *FluxGo) isServing(role Role) bool {
+	return !f.commandMode && f.HasRole(role)
+}
+
+func parseRoles(values []string) ([]Role, error) {
+	roles := make([]Role, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			roles = append(roles, Role(value))
+		}
+	}
+
+	return roles, validateRoles(roles)
+}
+
+// validateRoles rejects roles no subsystem answers to, which would silently disable them all.
+func validateRoles(roles []Role) error {
+	for _, role := range roles {
+		if !slices.Contains(knownRoles, role) {
+			return fmt.Errorf("unknown role %q, expected one of %v", role, knownRoles)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package fluxgo
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type rolesTestHandler struct{}
+
+func (h *rolesTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+}
+
+func rolesTestApp(t *testing.T, roles ...Role) *Http {
+	flux := New(FluxGoConfig{Name: "Test", Roles: roles})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{}, func(HttpConfigData) {})
+	flux.AddModule(Module("test").
+		AddHandler(func() *rolesTestHandler { return &rolesTestHandler{} }).
+		Route(GET[rolesTestHandler]("", "/ping", RouteIncome{})))
+
+	_, http := flux.GetTestApp(t)
+
+	return http
+}
+
+func TestRoles(t *testing.T) {
+	t.Run("Should enable every role when none is configured", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+
+		assert.True(t, flux.HasRole(RoleHttp))
+		assert.True(t, flux.HasRole(RoleKafka))
+	})
+
+	t.Run("Should enable only the configured roles", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test", Roles: []Role{RoleKafka, RoleCron}})
+
+		assert.False(t, flux.HasRole(RoleHttp))
+		assert.False(t, flux.HasRole(RoleGrpc))
+		assert.True(t, flux.HasRole(RoleKafka))
+		assert.True(t, flux.HasRole(RoleCron))
+	})
+
+	t.Run("Should read roles from the environment", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test", Env: &Env{Roles: []string{"cron"}}})
+
+		assert.Equal(t, []Role{RoleCron}, flux.Roles)
+	})
+
+	t.Run("Should reject unknown roles", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test", Env: &Env{Roles: []string{"http", "worker"}}})
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), `unknown role "worker"`)
+
+		flux = New(FluxGoConfig{Name: "Test", Roles: []Role{"htp"}})
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), `unknown role "htp"`)
+	})
+
+	t.Run("Should register HTTP routes only for the http role", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(rolesTestApp(t, RoleHttp), "GET", "/ping", nil, nil)
+		assert.Equal(t, 200, status)
+
+		status, _ = RunTestRequestRaw(rolesTestApp(t, RoleCron), "GET", "/ping", nil, nil)
+		assert.Equal(t, 404, status)
+	})
+}
@@ -2,7 +2,10 @@ package fluxgo
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 
+	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 )
 
@@ -10,6 +13,9 @@ import (
 // Each definition knows how to produce an fx.Option for dependency injection.
 type RouteDefinition interface {
 	toFxOption(m *FluxModule) fx.Option
+	role() Role
+	// requires lists the subsystems the definition resolves from fx.
+	requires() []Dependency
 }
 
 // --- HTTP Routes ---
@@ -25,30 +31,69 @@ type httpRouteDef struct {
 func (d *httpRouteDef) toFxOption(m *FluxModule) fx.Option {
 	return fx.Invoke(d.makeFn(m))
 }
+func (d *httpRouteDef) role() Role {
+	return RoleHttp
+}
+func (d *httpRouteDef) requires() []Dependency {
+	if (d.config.CacheTTL > 0 || d.config.Idempotent) && d.config.Cache == nil {
+		return []Dependency{DependencyHttp, DependencyApm, DependencyRedis}
+	}
+
+	return []Dependency{DependencyHttp, DependencyApm}
+}
 
 // HttpDef creates an HTTP route definition that auto-resolves handler *T from DI.
 // T is the concrete handler type; PT is the pointer type that implements HttpHandlers.
-// If CacheTTL is set and Cache is nil, Redis is auto-injected as the cache.
+// If CacheTTL or Idempotent is set and Cache is nil, Redis is auto-injected as the cache.
 //
 // Usage: HttpDef[MyHandler](group, method, path, config)
 func HttpDef[T any, PT interface {
 	*T
 	HttpHandlers
 }](group, method, path string, config RouteIncome) RouteDefinition {
-	needsCache := config.CacheTTL > 0 && config.Cache == nil
+	return httpDef(group, method, path, config, func(handler PT) routeHandler {
+		return routeHandler{http: handler.HandleHttp}
+	})
+}
+
+// httpRouteParams resolves the dependencies of an HTTP route; Redis backs CacheTTL, Idempotent and RateLimit when added.
+type httpRouteParams[PT any] struct {
+	fx.In
+
+	Flux    *FluxGo
+	Http    *Http
+	Apm     *Apm
+	Redis   *Redis `optional:"true"`
+	Handler PT
+}
+
+// routeHandler is the handler of an HTTP route. detached, when set, handles the request
+// without the fiber context, so it can outlive the request past its timeout.
+type routeHandler struct {
+	http     HttpHandler
+	detached func(ctx context.Context, income interface{}) (*GlobalResponse, *GlobalError)
+}
 
+// httpDef builds an HTTP route whose handler PT is resolved from DI and adapted by toHandler.
+func httpDef[PT any](group, method, path string, config RouteIncome, toHandler func(handler PT) routeHandler) RouteDefinition {
 	return &httpRouteDef{
 		group: group, method: method, path: path, config: config,
 		makeFn: func(m *FluxModule) interface{} {
-			if needsCache {
-				return func(f *FluxGo, http *Http, apm *Apm, redis *Redis, handler PT) error {
-					cfg := config
-					cfg.Cache = redis
-					return m.HttpRoute(f, http, apm, group, method, path, cfg, handler.HandleHttp)
+			return func(p httpRouteParams[PT]) error {
+				cfg := config
+				if (cfg.CacheTTL > 0 || cfg.Idempotent) && cfg.Cache == nil {
+					if p.Redis == nil {
+						return fmt.Errorf("route %s %s%s: CacheTTL and Idempotent require FluxGo.AddRedis or RouteIncome.Cache", method, group, path)
+					}
+					cfg.Cache = p.Redis
 				}
-			}
-			return func(f *FluxGo, http *Http, apm *Apm, handler PT) error {
-				return m.HttpRoute(f, http, apm, group, method, path, config, handler.HandleHttp)
+				if cfg.RateLimit != nil && cfg.RateLimit.Store == nil && p.Redis != nil {
+					rateLimit := *cfg.RateLimit
+					rateLimit.Store = NewRedisRateLimitStore(p.Redis)
+					cfg.RateLimit = &rateLimit
+				}
+
+				return m.httpRoute(p.Flux, p.Http, p.Apm, group, method, path, cfg, toHandler(p.Handler))
 			}
 		},
 	}
@@ -94,6 +139,127 @@ func DELETE[T any, PT interface {
 	return HttpDef[T, PT](group, "DELETE", path, config)
 }
 
+// --- Typed HTTP Routes ---
+
+// TypedHttpHandler handles a request parsed into Req and answers with Res.
+type TypedHttpHandler[Req any, Res any] interface {
+	Handle(ctx context.Context, req *Req) (*Res, *GlobalError)
+}
+
+// StatusCoder is implemented by typed responses answering with a status other than 200,
+// e.g. 201 after a create or 202 when the work is queued.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// TypedHttpDef creates an HTTP route whose handler receives *Req instead of interface{}.
+// RouteIncome.Entity is inferred from Req and RouteDoc.OkResponse from Res, or
+// RouteDoc.CreatedResponse when Res answers 201.
+// A non-nil *Res is sent with status 200, or its StatusCode when it is a StatusCoder;
+// a nil one with 204.
+//
+// Usage: TypedHttpDef[GetUser, dto.GetUserReq, dto.GetUserRes](group, method, path, config)
+func TypedHttpDef[T any, Req any, Res any, PT interface {
+	*T
+	TypedHttpHandler[Req, Res]
+}](group, method, path string, config RouteIncome) RouteDefinition {
+	return typedHttpDef(group, method, path, config, func(handler PT) TypedHttpHandler[Req, Res] {
+		return handler
+	})
+}
+
+func typedHttpDef[Req any, Res any, PT any](group, method, path string, config RouteIncome, adapt func(handler PT) TypedHttpHandler[Req, Res]) RouteDefinition {
+	config.Entity = *new(Req)
+
+	doc := RouteDoc{}
+	if config.Doc != nil {
+		doc = *config.Doc
+	}
+	if doc.OkResponse == nil && doc.CreatedResponse == nil {
+		if coder, ok := any(new(Res)).(StatusCoder); ok && coder.StatusCode() == fiber.StatusCreated {
+			doc.CreatedResponse = *new(Res)
+		} else {
+			doc.OkResponse = *new(Res)
+		}
+	}
+	config.Doc = &doc
+
+	return httpDef(group, method, path, config, func(handler PT) routeHandler {
+		return typedHttpHandler(adapt(handler))
+	})
+}
+
+func typedHttpHandler[Req any, Res any](handler TypedHttpHandler[Req, Res]) routeHandler {
+	handle := func(ctx context.Context, income interface{}) (*GlobalResponse, *GlobalError) {
+		req, ok := income.(*Req)
+		if !ok || req == nil {
+			req = new(Req)
+		}
+
+		res, err := handler.Handle(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return &GlobalResponse{Status: fiber.StatusNoContent}, nil
+		}
+
+		status := fiber.StatusOK
+		if coder, ok := any(res).(StatusCoder); ok && coder.StatusCode() != 0 {
+			status = coder.StatusCode()
+		}
+
+		return &GlobalResponse{Status: status, Content: res}, nil
+	}
+
+	return routeHandler{
+		http: func(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+			return handle(c.UserContext(), income)
+		},
+		detached: handle,
+	}
+}
+
+// TypedGET creates a typed HTTP GET route definition.
+func TypedGET[T any, Req any, Res any, PT interface {
+	*T
+	TypedHttpHandler[Req, Res]
+}](group, path string, config RouteIncome) RouteDefinition {
+	return TypedHttpDef[T, Req, Res, PT](group, "GET", path, config)
+}
+
+// TypedPOST creates a typed HTTP POST route definition.
+func TypedPOST[T any, Req any, Res any, PT interface {
+	*T
+	TypedHttpHandler[Req, Res]
+}](group, path string, config RouteIncome) RouteDefinition {
+	return TypedHttpDef[T, Req, Res, PT](group, "POST", path, config)
+}
+
+// TypedPUT creates a typed HTTP PUT route definition.
+func TypedPUT[T any, Req any, Res any, PT interface {
+	*T
+	TypedHttpHandler[Req, Res]
+}](group, path string, config RouteIncome) RouteDefinition {
+	return TypedHttpDef[T, Req, Res, PT](group, "PUT", path, config)
+}
+
+// TypedPATCH creates a typed HTTP PATCH route definition.
+func TypedPATCH[T any, Req any, Res any, PT interface {
+	*T
+	TypedHttpHandler[Req, Res]
+}](group, path string, config RouteIncome) RouteDefinition {
+	return TypedHttpDef[T, Req, Res, PT](group, "PATCH", path, config)
+}
+
+// TypedDELETE creates a typed HTTP DELETE route definition.
+func TypedDELETE[T any, Req any, Res any, PT interface {
+	*T
+	TypedHttpHandler[Req, Res]
+}](group, path string, config RouteIncome) RouteDefinition {
+	return TypedHttpDef[T, Req, Res, PT](group, "DELETE", path, config)
+}
+
 // --- Cron Routes ---
 
 type cronRouteDef struct {
@@ -103,6 +269,12 @@ type cronRouteDef struct {
 func (d *cronRouteDef) toFxOption(m *FluxModule) fx.Option {
 	return fx.Invoke(d.makeFn(m))
 }
+func (d *cronRouteDef) role() Role {
+	return RoleCron
+}
+func (d *cronRouteDef) requires() []Dependency {
+	return []Dependency{DependencyCron}
+}
 
 // CronDef creates a cron route that calls handler T's HandleCron method.
 // T must implement CronHandlerInterface (use pointer type).
@@ -117,12 +289,22 @@ func CronDef[T any, PT interface {
 	return &cronRouteDef{
 		makeFn: func(m *FluxModule) interface{} {
 			return func(cron *Cron, handler PT) error {
-				return m.CronRoute(cron, crontab, handler.HandleCron)
+				return m.CronRouteNamed(cron, cronJobName[T](m), crontab, handler.HandleCron)
 			}
 		},
 	}
 }
 
+// handlerTypeName returns the Go type name of a handler, e.g. "HandlerGetUser".
+func handlerTypeName[T any]() string {
+	return reflect.TypeFor[T]().Name()
+}
+
+// cronJobName identifies a CronDef job as "<module>.<HandlerType>", e.g. "user.HandlerGetUser".
+func cronJobName[T any](m *FluxModule) string {
+	return fmt.Sprintf("%s.%s", m.Name, handlerTypeName[T]())
+}
+
 // CronFn creates a cron route with an inline function.
 // The function receives dependencies from fx and must return a CronHandler.
 func CronFn(invokeFn interface{}) RouteDefinition {
@@ -142,6 +324,12 @@ type topicRouteDef struct {
 func (d *topicRouteDef) toFxOption(m *FluxModule) fx.Option {
 	return fx.Invoke(d.makeFn(m))
 }
+func (d *topicRouteDef) role() Role {
+	return RoleKafka
+}
+func (d *topicRouteDef) requires() []Dependency {
+	return []Dependency{DependencyKafka, DependencyApm}
+}
 
 // TopicDef creates a Kafka consumer route that calls handler T's HandleMessage method.
 // T is the concrete type; PT is the pointer type that implements ConsumerInterface.
@@ -152,7 +340,12 @@ func TopicDef[T any, PT interface {
 	return &topicRouteDef{
 		makeFn: func(m *FluxModule) interface{} {
 			return func(kafka *Kafka, handler PT) error {
-				return m.TopicConsume(kafka, topic, handler.HandleMessage)
+				return kafka.addConsumer(Consumer{
+					topic:       topic,
+					handler:     handler.HandleMessage,
+					module:      m.Name,
+					handlerType: handlerTypeName[T](),
+				})
 			}
 		},
 	}
@@ -167,6 +360,12 @@ type grpcRouteDef struct {
 func (d *grpcRouteDef) toFxOption(m *FluxModule) fx.Option {
 	return fx.Invoke(d.makeFn(m))
 }
+func (d *grpcRouteDef) role() Role {
+	return RoleGrpc
+}
+func (d *grpcRouteDef) requires() []Dependency {
+	return []Dependency{DependencyGrpc}
+}
 
 // GrpcDef creates a gRPC route definition that registers handler T on the gRPC server.
 // T must implement GrpcHandlerInterface; PT is the pointer type resolved from DI.
@@ -194,6 +393,12 @@ type toolRouteDef struct {
 func (d *toolRouteDef) toFxOption(m *FluxModule) fx.Option {
 	return fx.Invoke(d.makeFn(m))
 }
+func (d *toolRouteDef) role() Role {
+	return ""
+}
+func (d *toolRouteDef) requires() []Dependency {
+	return []Dependency{DependencyTools}
+}
 
 // ToolDef creates a tool route that registers handler T as a tool.
 // T must implement ToolsInterface (use pointer type).
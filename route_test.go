@@ -0,0 +1,99 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type routeTestReq struct {
+	Id   string `params:"id"`
+	Name string `json:"name" validate:"required"`
+}
+type routeTestRes struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type routeTestCreated struct {
+	Id string `json:"id"`
+}
+
+func (r *routeTestCreated) StatusCode() int { return 201 }
+
+type routeTestHandler struct{}
+
+func (h *routeTestHandler) Handle(ctx context.Context, req *routeTestReq) (*routeTestRes, *GlobalError) {
+	switch req.Id {
+	case "missing":
+		return nil, ErrorNotFound("User not found")
+	case "empty":
+		return nil, nil
+	}
+	return &routeTestRes{Id: req.Id, Name: req.Name}, nil
+}
+
+type routeTestCreateHandler struct{}
+
+func (h *routeTestCreateHandler) Handle(ctx context.Context, req *routeTestReq) (*routeTestCreated, *GlobalError) {
+	return &routeTestCreated{Id: "2"}, nil
+}
+
+func TestTypedHttpRoutes(t *testing.T) {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{}, func(HttpConfigData) {})
+	flux.AddModule(Module("test").
+		AddHandler(func() *routeTestHandler { return &routeTestHandler{} }).
+		AddHandler(func() *routeTestCreateHandler { return &routeTestCreateHandler{} }).
+		Route(
+			TypedPUT[routeTestHandler, routeTestReq, routeTestRes]("", "/users/:id", RouteIncome{FromParam: true, FromBody: true, Validate: true}),
+			TypedPOST[routeTestCreateHandler, routeTestReq, routeTestCreated]("", "/users", RouteIncome{FromBody: true}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should pass the parsed request and send the typed response", func(t *testing.T) {
+		status, body := RunTestRequestRaw(http, "PUT", "/users/1", map[string]string{"name": "John"}, nil)
+
+		var res routeTestRes
+		assert.NoError(t, json.Unmarshal(body, &res))
+		assert.Equal(t, 200, status)
+		assert.Equal(t, routeTestRes{Id: "1", Name: "John"}, res)
+	})
+
+	t.Run("Should validate the inferred entity", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "PUT", "/users/1", map[string]string{}, nil)
+
+		assert.Equal(t, 400, status)
+	})
+
+	t.Run("Should send handler errors and empty responses", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "PUT", "/users/missing", map[string]string{"name": "John"}, nil)
+		assert.Equal(t, 404, status)
+
+		status, _ = RunTestRequestRaw(http, "PUT", "/users/empty", map[string]string{"name": "John"}, nil)
+		assert.Equal(t, 204, status)
+	})
+
+	t.Run("Should send the status of a StatusCoder response", func(t *testing.T) {
+		status, body := RunTestRequestRaw(http, "POST", "/users", map[string]string{"name": "John"}, nil)
+
+		assert.Equal(t, 201, status)
+		assert.JSONEq(t, `{"id":"2"}`, string(body))
+
+		spec, err := json.Marshal(http.OpenAPISpec(""))
+		assert.NoError(t, err)
+		assert.Contains(t, string(spec), `"201":{"content":{"application/json":{"schema":{"additionalProperties":false,"properties":{"id"`)
+	})
+
+	t.Run("Should document the request and response types", func(t *testing.T) {
+		spec, err := json.Marshal(http.OpenAPISpec(""))
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(spec), `"requestBody":{"content":{"application/json":{"schema":{"additionalProperties":false,"properties":{"name"`)
+		assert.Contains(t, string(spec), `"200":{"content":{"application/json":{"schema":{"additionalProperties":false,"properties":{"id"`)
+	})
+}
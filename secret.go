@@ -0,0 +1,373 @@
+package fluxgo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// SecretProvider resolves a secret reference such as "db/password" to its current value.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, key string) (string, error)
+}
+
+// FileSecretProvider reads each secret from a file named after its key under Dir,
+// the layout of Kubernetes secret volumes and Docker secrets (/run/secrets).
+type FileSecretProvider struct {
+	Dir string
+}
+
+func (p FileSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	path, err := secretPath(p.Dir, key)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", key, err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func secretPath(dir, key string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(key))
+	if rel, err := filepath.Rel(dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid secret key: %s", key)
+	}
+
+	return path, nil
+}
+
+// EncryptedFileSecretProvider reads secrets from a JSON object of key/value pairs
+// encrypted with AES-GCM, see EncryptSecrets. The file is read on every lookup so
+// replacing it rotates the secrets.
+type EncryptedFileSecretProvider struct {
+	Path string
+	// Key is the 16, 24 or 32 bytes AES key.
+	Key []byte
+}
+
+func (p EncryptedFileSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	content, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	gcm, err := newSecretCipher(p.Key)
+	if err != nil {
+		return "", err
+	}
+	if len(content) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid secrets file: %s", p.Path)
+	}
+
+	nonce, ciphertext := content[:gcm.NonceSize()], content[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secrets file: %w", err)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return "", fmt.Errorf("invalid secrets file: %w", err)
+	}
+
+	value, exists := secrets[key]
+	if !exists {
+		return "", fmt.Errorf("secret not found: %s", key)
+	}
+
+	return value, nil
+}
+
+// EncryptSecrets produces the content of a file read by EncryptedFileSecretProvider.
+func EncryptSecrets(key []byte, secrets map[string]string) ([]byte, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// HttpSecretProvider fetches GET {URL}/{key} and expects a JSON body like {"value": "..."}.
+type HttpSecretProvider struct {
+	URL string
+	// Headers are sent on every request, e.g. an Authorization token.
+	Headers map[string]string
+	// Client defaults to an http.Client with a 10 second timeout.
+	Client *http.Client
+}
+
+func (p HttpSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	// Each segment is escaped on its own so hierarchical keys such as db/password keep their path.
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	endpoint := strings.TrimRight(p.URL, "/") + "/" + strings.Join(segments, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	for name, value := range p.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret %s: %w", key, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch secret %s: status %d", key, res.StatusCode)
+	}
+
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid secret response for %s: %w", key, err)
+	}
+
+	return body.Value, nil
+}
+
+type SecretsOptions struct {
+	// RefreshInterval re-fetches every secret read so far and fires OnRotate callbacks
+	// for the ones that changed. Zero disables refreshing.
+	RefreshInterval time.Duration
+}
+
+// Secrets caches values read from a SecretProvider and notifies rotations.
+type Secrets struct {
+	provider SecretProvider
+	opt      SecretsOptions
+
+	mu        sync.RWMutex
+	values    map[string]string
+	callbacks map[string][]func(value string)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewSecrets(provider SecretProvider, opt SecretsOptions) *Secrets {
+	return &Secrets{
+		provider:  provider,
+		opt:       opt,
+		values:    map[string]string{},
+		callbacks: map[string][]func(value string){},
+	}
+}
+
+// AddSecrets provides *Secrets and refreshes it in the background.
+// Call it before AddDatabase or AddRedis when their credentials come from secrets.
+func (f *FluxGo) AddSecrets(provider SecretProvider, opt SecretsOptions) *FluxGo {
+	f.secrets = NewSecrets(provider, opt)
+
+	f.AddDependency(func() *Secrets {
+		return f.secrets
+	})
+	f.addResource(func(lc fx.Lifecycle, secrets *Secrets) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				secrets.watch(func(err error) {
+					f.LogError("SECRETS", fmt.Sprintf("Refresh failed: %v", err))
+				})
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				secrets.unwatch()
+				return nil
+			},
+		})
+	})
+
+	return f
+}
+
+// Get returns the cached value of key, fetching it from the provider on first use.
+func (s *Secrets) Get(ctx context.Context, key string) (string, error) {
+	s.mu.RLock()
+	value, exists := s.values[key]
+	s.mu.RUnlock()
+
+	if exists {
+		return value, nil
+	}
+
+	value, err := s.provider.GetSecret(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+
+	return value, nil
+}
+
+// OnRotate calls fn with the new value whenever a refresh finds that key changed.
+func (s *Secrets) OnRotate(key string, fn func(value string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.callbacks[key] = append(s.callbacks[key], fn)
+}
+
+// Refresh re-fetches every cached secret. Secrets that fail to load keep their previous value.
+func (s *Secrets) Refresh(ctx context.Context) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+
+	var errs []error
+
+	for _, key := range keys {
+		value, err := s.provider.GetSecret(ctx, key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s.mu.Lock()
+		changed := s.values[key] != value
+		s.values[key] = value
+		callbacks := append([]func(value string){}, s.callbacks[key]...)
+		s.mu.Unlock()
+
+		if changed {
+			for _, fn := range callbacks {
+				fn(value)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Secrets) watch(onError func(err error)) {
+	if s.opt.RefreshInterval <= 0 {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.opt.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := s.Refresh(context.Background()); err != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *Secrets) unwatch() {
+	if s.stop == nil {
+		return
+	}
+
+	close(s.stop)
+	<-s.done
+}
+
+// resolveSecretTags fills string fields tagged `secret:"key"` from provider, walking nested structs.
+func resolveSecretTags(ctx context.Context, value reflect.Value, provider SecretProvider) []error {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []error
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, tagged := field.Tag.Lookup("secret")
+		if !tagged {
+			errs = append(errs, resolveSecretTags(ctx, value.Field(i), provider)...)
+			continue
+		}
+
+		if field.Type.Kind() != reflect.String {
+			errs = append(errs, fmt.Errorf("%s: secret tag requires a string field", field.Name))
+			continue
+		}
+
+		secret, err := provider.GetSecret(ctx, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.Name, err))
+			continue
+		}
+		value.Field(i).SetString(secret)
+	}
+
+	return errs
+}
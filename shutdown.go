@@ -0,0 +1,95 @@
+package fluxgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// ShutdownOptions configures the coordinated stop sequence:
+// readiness fails, PreStopDelay elapses, HTTP/gRPC/Kafka/cron stop accepting work,
+// in-flight handlers and background goroutines drain, then Database/Redis/Otel close.
+type ShutdownOptions struct {
+	// PreStopDelay keeps serving traffic after /readyz starts failing so load balancers
+	// have time to deregister the instance.
+	PreStopDelay time.Duration
+	// Timeout bounds the whole stop sequence. Default: fx default (15s).
+	Timeout time.Duration
+}
+
+type shutdown struct {
+	tasks sync.WaitGroup
+}
+
+// Go runs fn in a goroutine that shutdown waits for before closing Database, Redis and Otel.
+func (f *FluxGo) Go(fn func()) {
+	f.shutdown.tasks.Add(1)
+
+	go func() {
+		defer f.shutdown.tasks.Done()
+		fn()
+	}()
+}
+
+// addResource registers lifecycle hooks of infrastructure (Otel, Database, Redis, ...).
+// Resources start before and stop after every ingress subsystem and module.
+func (f *FluxGo) addResource(constructors ...interface{}) *FluxGo {
+	f.resources = append(f.resources, fx.Invoke(constructors...))
+
+	return f
+}
+
+// drainOption stops after every ingress subsystem and before any resource,
+// waiting for background goroutines started through FluxGo.Go.
+func (f *FluxGo) drainOption() fx.Option {
+	return fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				done := make(chan struct{})
+
+				go func() {
+					f.shutdown.tasks.Wait()
+					close(done)
+				}()
+
+				select {
+				case <-done:
+					f.Log("SHUTDOWN", "Background tasks drained")
+					return nil
+				case <-ctx.Done():
+					return fmt.Errorf("background tasks did not finish before shutdown deadline: %w", ctx.Err())
+				}
+			},
+		})
+	})
+}
+
+// preStopOption stops before everything else, flipping readiness and waiting PreStopDelay.
+func (f *FluxGo) preStopOption() fx.Option {
+	return fx.Invoke(func(lc fx.Lifecycle, health *Health) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				health.SetDraining(false)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				health.SetDraining(true)
+				f.Log("SHUTDOWN", "Readiness set to draining")
+
+				if f.Shutdown == nil || f.Shutdown.PreStopDelay <= 0 {
+					return nil
+				}
+
+				select {
+				case <-time.After(f.Shutdown.PreStopDelay):
+				case <-ctx.Done():
+				}
+
+				return nil
+			},
+		})
+	})
+}
@@ -0,0 +1,56 @@
+package fluxgo
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Run("Should wait for background tasks before stopping resources", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+
+		var finished atomic.Bool
+		var finishedBeforeResource atomic.Bool
+
+		flux.addResource(func(lc fx.Lifecycle) {
+			lc.Append(fx.Hook{OnStop: func(ctx context.Context) error {
+				finishedBeforeResource.Store(finished.Load())
+				return nil
+			}})
+		})
+
+		app := fxtest.New(t, flux.GetFxConfig()...)
+		app.RequireStart()
+
+		flux.Go(func() {
+			time.Sleep(50 * time.Millisecond)
+			finished.Store(true)
+		})
+
+		app.RequireStop()
+
+		assert.True(t, finished.Load())
+		assert.True(t, finishedBeforeResource.Load())
+	})
+
+	t.Run("Should mark readiness as draining when stopping", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test", Shutdown: &ShutdownOptions{PreStopDelay: 10 * time.Millisecond}})
+
+		app := fxtest.New(t, flux.GetFxConfig()...)
+		app.RequireStart()
+
+		assert.True(t, flux.health.Check(context.Background()).IsHealthy())
+
+		app.RequireStop()
+
+		report := flux.health.Check(context.Background())
+		assert.False(t, report.IsHealthy())
+		assert.True(t, report.Draining)
+	})
+}
@@ -0,0 +1,304 @@
+package fluxgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultSSEHeartbeat = 15 * time.Second
+
+// ErrSSEClosed is returned by SSEStream once the stream is closed.
+var ErrSSEClosed = errors.New("sse stream closed")
+
+// SSEHandler streams events of type E to a client until the context is cancelled,
+// which happens when the client disconnects or the application shuts down.
+type SSEHandler[E any] interface {
+	HandleSSE(ctx context.Context, income interface{}, stream *SSEStream[E]) error
+}
+
+type SSEConfig struct {
+	// RouteIncome configures the request opening the stream; Timeout, Cache and Idempotent
+	// are rejected since they cannot apply to a stream.
+	RouteIncome
+	// Heartbeat sends a comment when idle so proxies keep the connection open. Default: 15s
+	Heartbeat time.Duration
+}
+
+// SSEEvent is a Server-Sent Event; Data is sent as JSON.
+type SSEEvent[E any] struct {
+	ID    string
+	Event string
+	Data  E
+	// Retry tells the client how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// SSEStream is the typed sink of an SSE route.
+type SSEStream[E any] struct {
+	// LastEventID is the id of the last event the client received before reconnecting,
+	// from the Last-Event-ID header or the lastEventId query parameter, to resume from.
+	LastEventID string
+
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closed bool
+	cancel context.CancelFunc
+}
+
+// Send sends data as an event without id or name.
+func (s *SSEStream[E]) Send(data E) error {
+	return s.SendEvent(SSEEvent[E]{Data: data})
+}
+
+func (s *SSEStream[E]) SendEvent(event SSEEvent[E]) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", sseField(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", sseField(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	fmt.Fprintf(&b, "data: %s\n\n", data)
+
+	return s.write(b.String())
+}
+
+// write flushes message, cancelling the stream when the client is gone.
+func (s *SSEStream[E]) write(message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSSEClosed
+	}
+
+	if _, err := s.w.WriteString(message); err != nil {
+		s.cancel()
+		return err
+	}
+	if err := s.w.Flush(); err != nil {
+		s.cancel()
+		return err
+	}
+
+	return nil
+}
+
+// close stops every later write, since fasthttp reuses the writer once the stream ends.
+func (s *SSEStream[E]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+}
+
+func (s *SSEStream[E]) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sseField strips line breaks, which would end the field.
+func sseField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// SSE creates a Server-Sent Events route definition that auto-resolves handler *T from DI.
+// The request is checked and parsed like other HTTP routes before the stream opens.
+//
+// Usage: SSE[JobProgress, dto.Progress](group, path, config)
+func SSE[T any, E any, PT interface {
+	*T
+	SSEHandler[E]
+}](group, path string, config SSEConfig) RouteDefinition {
+	return &httpRouteDef{
+		group: group, method: fiber.MethodGet, path: path, config: config.RouteIncome,
+		makeFn: func(m *FluxModule) interface{} {
+			return func(f *FluxGo, http *Http, apm *Apm, handler PT) error {
+				return sseRoute[E](m, f, http, apm, group, path, config, handler)
+			}
+		},
+	}
+}
+
+// sseRoute registers an SSE route for handler, like FluxModule.HttpRoute.
+func sseRoute[E any](m *FluxModule, f *FluxGo, http *Http, apm *Apm, group, path string, config SSEConfig, handler SSEHandler[E]) error {
+	heartbeat := defaultDuration(config.Heartbeat, defaultSSEHeartbeat)
+	name := fmt.Sprintf("SSE %s%s", group, path)
+
+	if err := config.checkStream(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	rateLimitStore, err := config.rateLimitStore(http)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	fun := func(c *fiber.Ctx) (resErr error) {
+		defer func() {
+			if r := recover(); r != nil {
+				f.LogError("HTTP", fmt.Sprintf("Recovered panic on %s: %v", name, r))
+				resErr = http.SendError(c, ErrorInternalError("Internal server error"))
+			}
+		}()
+
+		if config.RateLimit != nil {
+			if err := config.RateLimit.check(c, f, rateLimitStore, fiber.MethodGet+":"+group+path); err != nil {
+				return http.SendError(c, err)
+			}
+		}
+
+		if err := http.checkPermission(c.UserContext(), config.Permission, nil); err != nil {
+			return http.SendError(c, err)
+		}
+
+		income, err := config.Parse(http, c)
+		if err != nil {
+			return http.SendError(c, err)
+		}
+
+		if config.Permission != nil && config.Permission.Resource != nil {
+			resource, err := config.Permission.Resource(c, income)
+			if err != nil {
+				return http.SendError(c, err)
+			}
+			if err := http.checkPermission(c.UserContext(), config.Permission, resource); err != nil {
+				return http.SendError(c, err)
+			}
+		}
+
+		lastEventID := c.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("lastEventId")
+		}
+
+		// The stream outlives the fiber handler, so it gets its own span linked to the request.
+		parent := c.UserContext()
+		ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+		ctx, span := apm.StartSpan(ctx, name, trace.WithLinks(trace.LinkFromContext(parent)))
+		span.SetAttributes(attribute.String("sse.last_event_id", lastEventID))
+		unregister := http.registerStream(cancel)
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			stream := &SSEStream[E]{LastEventID: lastEventID, w: w, cancel: cancel}
+			var heartbeats sync.WaitGroup
+
+			defer func() {
+				cancel()
+				heartbeats.Wait()
+				stream.close()
+				unregister()
+				span.End()
+			}()
+			// fasthttp runs the writer in its own goroutine, out of reach of the recover above.
+			defer func() {
+				if r := recover(); r != nil {
+					span.SetError(fmt.Errorf("panic: %v", r))
+					f.LogError("HTTP", fmt.Sprintf("Recovered panic on %s: %v", name, r))
+				}
+			}()
+
+			if err := stream.write(": connected\n\n"); err != nil {
+				return
+			}
+			heartbeats.Add(1)
+			go func() {
+				defer heartbeats.Done()
+				stream.heartbeat(ctx, heartbeat)
+			}()
+
+			if err := handler.HandleSSE(ctx, income, stream); err != nil && ctx.Err() == nil {
+				span.SetError(err)
+				f.LogError("HTTP", fmt.Sprintf("%s failed: %v", name, err))
+			}
+		})
+
+		return nil
+	}
+
+	docPath, err := config.addRoute(http, group, fiber.MethodGet, path, fun)
+	if err != nil {
+		return err
+	}
+
+	http.addRouteDoc(routeDoc{
+		method:      fiber.MethodGet,
+		path:        docPath,
+		version:     config.Version,
+		module:      m.Name,
+		permission:  config.Permission,
+		rateLimit:   config.RateLimit,
+		tags:        []string{m.swaggerTagName(http)},
+		doc:         config.Doc,
+		entity:      config.Entity,
+		fromQuery:   config.FromQuery,
+		fromParam:   config.FromParam,
+		fromHeader:  config.FromHeader,
+		streamEvent: *new(E),
+		stream:      true,
+	})
+
+	return nil
+}
+
+// registerStream tracks an open stream so Http.stop can close it; the returned func untracks it.
+func (h *Http) registerStream(cancel context.CancelFunc) func() {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+
+	if h.streams == nil {
+		h.streams = map[*context.CancelFunc]struct{}{}
+	}
+	key := &cancel
+	h.streams[key] = struct{}{}
+
+	return func() {
+		h.streamsMu.Lock()
+		defer h.streamsMu.Unlock()
+
+		delete(h.streams, key)
+	}
+}
+
+// closeStreams cancels every open stream, letting their handlers return before the server shuts down.
+func (h *Http) closeStreams() {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+
+	for cancel := range h.streams {
+		(*cancel)()
+	}
+}
@@ -0,0 +1,150 @@
+package fluxgo
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type sseTestProgress struct {
+	Percent int `json:"percent"`
+}
+
+type sseTestHandler struct{}
+
+func (h *sseTestHandler) HandleSSE(ctx context.Context, income interface{}, stream *SSEStream[sseTestProgress]) error {
+	start, _ := strconv.Atoi(stream.LastEventID)
+	for i := start + 1; i <= 3; i++ {
+		if err := stream.SendEvent(SSEEvent[sseTestProgress]{ID: strconv.Itoa(i), Event: "progress", Data: sseTestProgress{Percent: i * 10}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sseTestWaitHandler struct{}
+
+func (h *sseTestWaitHandler) HandleSSE(ctx context.Context, income interface{}, stream *SSEStream[sseTestProgress]) error {
+	<-ctx.Done()
+	return nil
+}
+
+type sseTestLeakHandler struct {
+	streams chan *SSEStream[sseTestProgress]
+}
+
+func (h *sseTestLeakHandler) HandleSSE(ctx context.Context, income interface{}, stream *SSEStream[sseTestProgress]) error {
+	if stream.LastEventID == "panic" {
+		panic("broken handler")
+	}
+	h.streams <- stream
+	return nil
+}
+
+func TestSSE(t *testing.T) {
+	leak := &sseTestLeakHandler{streams: make(chan *SSEStream[sseTestProgress], 1)}
+
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Permissions: &Permissions{"viewer": {{Action: "read", Subject: "job"}}}}, func(h HttpConfigData) {
+		h.CreateRouter("/api", WithMiddleware(func(c *fiber.Ctx) error {
+			c.SetUserContext(context.WithValue(c.UserContext(), RoleContextKey, c.Get("X-Role")))
+			return c.Next()
+		}))
+	})
+	flux.AddModule(Module("test").
+		AddHandler(func() *sseTestHandler { return &sseTestHandler{} }).
+		AddHandler(func() *sseTestWaitHandler { return &sseTestWaitHandler{} }).
+		AddHandler(func() *sseTestLeakHandler { return leak }).
+		Route(
+			SSE[sseTestHandler, sseTestProgress]("/api", "/jobs/progress", SSEConfig{RouteIncome: RouteIncome{Permission: &RoutePermission{Action: "read", Subject: "job"}}}),
+			SSE[sseTestWaitHandler, sseTestProgress]("/api", "/jobs/wait", SSEConfig{}),
+			SSE[sseTestLeakHandler, sseTestProgress]("/api", "/jobs/leak", SSEConfig{}),
+			SSE[sseTestHandler, sseTestProgress]("/api", "/jobs/limited", SSEConfig{RouteIncome: RouteIncome{RateLimit: &RateLimit{Limit: 1, Window: time.Minute}}}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	stream := func(path string, headers map[string]string) (int, string, string) {
+		req := httptest.NewRequest("GET", path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		res, err := http.GetApp().Test(req, 5000)
+		assert.NoError(t, err)
+
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, res.Header.Get("Content-Type"), string(body)
+	}
+
+	t.Run("Should stream typed events", func(t *testing.T) {
+		status, contentType, body := stream("/api/jobs/progress", map[string]string{"X-Role": "viewer"})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "text/event-stream", contentType)
+		assert.Contains(t, body, "id: 1\nevent: progress\ndata: {\"percent\":10}\n\n")
+		assert.Contains(t, body, "id: 3\nevent: progress\ndata: {\"percent\":30}\n\n")
+	})
+
+	t.Run("Should resume after Last-Event-ID", func(t *testing.T) {
+		_, _, body := stream("/api/jobs/progress", map[string]string{"X-Role": "viewer", "Last-Event-ID": "2"})
+		assert.NotContains(t, body, "id: 1\n")
+		assert.NotContains(t, body, "id: 2\n")
+		assert.Contains(t, body, "id: 3\n")
+	})
+
+	t.Run("Should check permissions before opening the stream", func(t *testing.T) {
+		status, _, _ := stream("/api/jobs/progress", nil)
+		assert.Equal(t, 401, status)
+	})
+
+	t.Run("Should rate limit opening the stream", func(t *testing.T) {
+		status, _, _ := stream("/api/jobs/limited", nil)
+		assert.Equal(t, 200, status)
+		status, _, _ = stream("/api/jobs/limited", nil)
+		assert.Equal(t, 429, status)
+	})
+
+	t.Run("Should reject route options a stream cannot honour", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(HttpConfigData) {})
+		flux.AddModule(Module("test").
+			AddHandler(func() *sseTestHandler { return &sseTestHandler{} }).
+			Route(SSE[sseTestHandler, sseTestProgress]("", "/jobs", SSEConfig{RouteIncome: RouteIncome{Timeout: time.Second}})))
+
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "Timeout is not supported on streaming routes")
+	})
+
+	t.Run("Should recover handler panics inside the stream", func(t *testing.T) {
+		status, _, body := stream("/api/jobs/leak", map[string]string{"Last-Event-ID": "panic"})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, ": connected\n\n", body)
+	})
+
+	t.Run("Should refuse writes once the stream ended", func(t *testing.T) {
+		stream("/api/jobs/leak", nil)
+		assert.ErrorIs(t, (<-leak.streams).Send(sseTestProgress{Percent: 100}), ErrSSEClosed)
+	})
+
+	t.Run("Should close open streams on shutdown", func(t *testing.T) {
+		go func() {
+			assert.Eventually(t, func() bool {
+				http.streamsMu.Lock()
+				defer http.streamsMu.Unlock()
+				return len(http.streams) > 0
+			}, time.Second, 5*time.Millisecond)
+			http.closeStreams()
+		}()
+
+		status, _, body := stream("/api/jobs/wait", nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, ": connected\n\n", body)
+	})
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/invopop/jsonschema"
 )
@@ -22,17 +24,37 @@ type SwaggerOptions struct {
 	Description string
 }
 
+// openAPIInfo holds the spec info block resolved from FluxGoConfig and SwaggerOptions.
+type openAPIInfo struct {
+	title       string
+	version     string
+	description string
+}
+
 // routeDoc holds the metadata collected for each registered HTTP route.
 type routeDoc struct {
 	method     string
 	path       string // full path: group + route, e.g. /public/user/:id
-	tags       []string
-	doc        *RouteDoc
-	entity     any
-	fromBody   bool
-	fromQuery  bool
-	fromParam  bool
-	fromHeader bool
+	module     string
+	permission *RoutePermission
+	cacheTTL   time.Duration
+	rateLimit  *RateLimit
+	idempotent bool
+	pagination *PaginationOptions
+	version    string
+	// stream routes reply text/event-stream with streamEvent as the data of each event,
+	// websocket routes exchange streamEvent messages.
+	stream      bool
+	websocket   bool
+	streamEvent any
+	tags        []string
+	doc         *RouteDoc
+	entity      any
+	fromBody    bool
+	fromForm    bool
+	fromQuery   bool
+	fromParam   bool
+	fromHeader  bool
 }
 
 var fiberParamRe = regexp.MustCompile(`:(\w+)`)
@@ -116,11 +138,23 @@ func responseObject(description string, d *RouteDoc, bodyFn func(*RouteDoc) any,
 	return obj
 }
 
+// OpenAPISpec returns the OpenAPI 3.0 document for routes under prefix ("" for every route).
+func (h *Http) OpenAPISpec(prefix string) map[string]any {
+	return h.buildOpenAPISpec(h.specInfo.title, h.specInfo.version, h.specInfo.description, prefix, "")
+}
+
+// OpenAPIVersionSpec returns the OpenAPI 3.0 document of API version under prefix:
+// the routes declaring that version and the routes without one.
+func (h *Http) OpenAPIVersionSpec(prefix, version string) map[string]any {
+	return h.buildOpenAPISpec(h.specInfo.title, version, h.specInfo.description, prefix, version)
+}
+
 // buildOpenAPISpec generates an OpenAPI 3.0 spec from all collected route docs.
 // Spec generation is lazy: called on first request to /{group}/swagger/openapi.json,
 // ensuring all routes are already registered.
-// prefix filters routes to only those under that group (e.g. "/public").
-func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[string]any {
+// prefix filters routes to only those under that group (e.g. "/public"), and apiVersion
+// to the routes of that version ("" for every version).
+func (h *Http) buildOpenAPISpec(title, version, description, prefix, apiVersion string) map[string]any {
 	paths := map[string]any{}
 	components := map[string]any{} // shared schemas accumulator
 
@@ -128,6 +162,9 @@ func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[
 		if prefix != "" && !strings.HasPrefix(doc.path, prefix) {
 			continue
 		}
+		if apiVersion != "" && doc.version != "" && doc.version != apiVersion {
+			continue
+		}
 		oaPath := fiberPathToOpenAPI(doc.path)
 
 		if _, ok := paths[oaPath]; !ok {
@@ -146,6 +183,43 @@ func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[
 			"422": map[string]any{"description": "Validation Error"},
 			"500": map[string]any{"description": "Internal Server Error"},
 		}
+		if doc.rateLimit != nil {
+			responses["429"] = rateLimitResponse(*doc.rateLimit)
+		}
+		if doc.idempotent {
+			responses["409"] = map[string]any{"description": "Conflict: a request with the same Idempotency-Key is in progress"}
+			responses["422"] = map[string]any{"description": "Validation Error, or Idempotency-Key reused with a different body"}
+		}
+		if doc.pagination != nil {
+			responses["200"].(map[string]any)["headers"] = map[string]any{
+				"Link": map[string]any{
+					"description": "RFC 8288 links to the first, prev, next and last pages",
+					"schema":      map[string]any{"type": "string"},
+				},
+			}
+		}
+		if doc.fromForm {
+			responses["413"] = map[string]any{"description": "Payload Too Large"}
+			responses["415"] = map[string]any{"description": "Unsupported Media Type"}
+		}
+		if doc.stream {
+			responses["200"] = map[string]any{
+				"description": "Server-Sent Events stream",
+				"content": map[string]any{
+					"text/event-stream": map[string]any{"schema": schemaRef(doc.streamEvent, components)},
+				},
+			}
+		}
+		if doc.websocket {
+			delete(responses, "200")
+			responses["101"] = map[string]any{
+				"description": "Switching Protocols: WebSocket exchanging JSON messages",
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaRef(doc.streamEvent, components)},
+				},
+			}
+			responses["426"] = map[string]any{"description": "Upgrade Required"}
+		}
 		if doc.doc != nil && doc.doc.CreatedResponse != nil {
 			responses["201"] = responseObject("Created", doc.doc, func(d *RouteDoc) any { return d.CreatedResponse }, components)
 		}
@@ -169,6 +243,11 @@ func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[
 				operation["deprecated"] = true
 			}
 		}
+		if doc.version != "" && h.versioning != nil {
+			if version, _ := h.versioning.version(doc.version); !version.Deprecated.IsZero() {
+				operation["deprecated"] = true
+			}
+		}
 
 		// Index entity fields by name (json/query/header tag or lowercase) for lookup.
 		entityFields := map[string]reflect.StructField{}
@@ -211,6 +290,19 @@ func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[
 			}
 		}
 
+		if doc.version != "" && h.versioning != nil && h.versioning.Strategy != VersionByPath {
+			params = append(params, versionParam(*h.versioning, doc.version))
+		}
+		if doc.idempotent {
+			params = append(params, map[string]any{
+				"name":        IdempotencyKeyHeader,
+				"in":          "header",
+				"required":    false,
+				"description": "Repeating a request with the same key replays its first response",
+				"schema":      map[string]any{"type": "string"},
+			})
+		}
+
 		pathsParams := extractPathParams(doc.path)
 		for _, p := range pathsParams {
 			pathParamSet[p] = true
@@ -279,6 +371,16 @@ func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[
 			}
 		}
 
+		if doc.pagination != nil {
+			params = documentPagination(params, *doc.pagination)
+		}
+
+		if doc.fromForm && doc.entity != nil {
+			if body := formRequestBody(reflect.TypeOf(doc.entity)); body != nil {
+				operation["requestBody"] = body
+			}
+		}
+
 		if len(params) > 0 {
 			operation["parameters"] = params
 		}
@@ -320,6 +422,107 @@ func (h *Http) buildOpenAPISpec(title, version, description, prefix string) map[
 	return spec
 }
 
+// documentPagination adds the limits and sort fields of a route to its PageRequest parameters,
+// dropping the page parameter in PageByCursor mode and the cursor in PageByOffset mode.
+func documentPagination(params []any, opt PaginationOptions) []any {
+	documented := make([]any, 0, len(params))
+	for _, p := range params {
+		param, ok := p.(map[string]any)
+		if !ok || param["in"] != "query" {
+			documented = append(documented, p)
+			continue
+		}
+		schema, _ := param["schema"].(map[string]any)
+
+		switch param["name"] {
+		case "limit":
+			schema["default"] = defaultInt(opt.DefaultLimit, defaultPageLimit)
+			schema["maximum"] = defaultInt(opt.MaxLimit, defaultPageMaxLimit)
+		case "sort":
+			fields := append([]string{}, opt.SortFields...)
+			for _, field := range (PageRequest{Sort: opt.DefaultSort}).SortFields() {
+				if !slices.Contains(fields, field.Field) {
+					fields = append(fields, field.Field)
+				}
+			}
+			param["description"] = fmt.Sprintf("Comma-separated fields among %s; prefix with - to sort descending", strings.Join(fields, ", "))
+			if opt.DefaultSort != "" {
+				schema["default"] = opt.DefaultSort
+			}
+		case "page":
+			if opt.Mode == PageByCursor {
+				continue
+			}
+		case "cursor":
+			if opt.Mode != PageByCursor {
+				continue
+			}
+		}
+		documented = append(documented, param)
+	}
+
+	return documented
+}
+
+func defaultInt(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+
+	return fallback
+}
+
+// formRequestBody documents the form-tagged fields of entity as a multipart/form-data body,
+// with files as binary strings.
+func formRequestBody(entity reflect.Type) map[string]any {
+	if entity.Kind() == reflect.Ptr {
+		entity = entity.Elem()
+	}
+	if entity.Kind() != reflect.Struct {
+		return nil
+	}
+
+	properties := map[string]any{}
+	required := []string{}
+	for _, field := range flattenFields(entity) {
+		name := tagValue(field, "form")
+		if name == "" {
+			continue
+		}
+
+		binary := map[string]any{"type": "string", "format": "binary"}
+		switch {
+		case field.Type == fileHeaderType:
+			properties[name] = binary
+		case field.Type == fileHeaderSliceType:
+			properties[name] = map[string]any{"type": "array", "items": binary}
+		case field.Type.Kind() == reflect.Interface && multipartFileType.Implements(field.Type):
+			properties[name] = binary
+		default:
+			properties[name] = fieldSchema(field)
+		}
+
+		if strings.Contains(field.Tag.Get("validate"), "required") {
+			required = append(required, name)
+		}
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"multipart/form-data": map[string]any{"schema": schema},
+		},
+	}
+}
+
 // buildParam builds an OpenAPI parameter object.
 // description is hoisted to the parameter level (not inside schema).
 func buildParam(name, in string, required bool, f reflect.StructField) map[string]any {
@@ -448,3 +651,40 @@ func swaggerUIHTML(specURL string) string {
   </body>
 </html>`, specURL)
 }
+
+// rateLimitResponse documents the 429 response and the RateLimit-* headers of a rate limited route.
+func rateLimitResponse(limit RateLimit) map[string]any {
+	header := func(description string) map[string]any {
+		return map[string]any{"description": description, "schema": map[string]any{"type": "integer"}}
+	}
+
+	return map[string]any{
+		"description": fmt.Sprintf("Too Many Requests: limited to %d requests per %s", limit.limit(), limit.Window),
+		"headers": map[string]any{
+			"Retry-After":         header("Seconds until a new request is accepted"),
+			"RateLimit-Limit":     header("Requests allowed in the window"),
+			"RateLimit-Remaining": header("Requests left in the window"),
+			"RateLimit-Reset":     header("Seconds until the window resets"),
+		},
+	}
+}
+
+// versionParam documents how a request selects version.
+func versionParam(versioning VersioningOptions, version string) map[string]any {
+	if versioning.Strategy == VersionByMediaType {
+		return map[string]any{
+			"name":        "Accept",
+			"in":          "header",
+			"required":    versioning.Default == "",
+			"description": fmt.Sprintf("application/json; version=%s", version),
+			"schema":      map[string]any{"type": "string"},
+		}
+	}
+
+	return map[string]any{
+		"name":     versioning.header(),
+		"in":       "header",
+		"required": versioning.Default == "",
+		"schema":   map[string]any{"type": "string", "enum": []string{version}},
+	}
+}
@@ -0,0 +1,129 @@
+package fluxgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// timeout returns the deadline of the route, falling back to HttpOptions.RouteTimeout.
+func (i *RouteIncome) timeout(http *Http) time.Duration {
+	if i.Timeout != 0 {
+		return max(i.Timeout, 0)
+	}
+
+	return http.routeTimeout
+}
+
+// withTimeout sets a deadline on the request context, so database, Redis and gRPC
+// calls made with c.UserContext() are cancelled once it passes.
+func (i *RouteIncome) withTimeout(c *fiber.Ctx, http *Http) context.CancelFunc {
+	timeout := i.timeout(http)
+	if timeout <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+	c.SetUserContext(ctx)
+
+	return cancel
+}
+
+// errRouteTimedOut is matched by the routeTimedOut returned once the 504 was sent while the
+// handler still runs.
+var errRouteTimedOut = errors.New("route timed out")
+
+// handlerResult is the outcome of a handler run by runHandler.
+type handlerResult struct {
+	res       *GlobalResponse
+	gErr      *GlobalError
+	recovered any
+}
+
+// routeTimedOut reports a 504 sent while a detached handler still runs; result receives
+// its outcome once it finishes.
+type routeTimedOut struct {
+	sendErr error
+	result  <-chan handlerResult
+}
+
+func (e *routeTimedOut) Error() string        { return errRouteTimedOut.Error() }
+func (e *routeTimedOut) Is(target error) bool { return target == errRouteTimedOut }
+func (e *routeTimedOut) Unwrap() error        { return e.sendErr }
+
+// runHandler runs handler within the request deadline. A handler that does not need c, such
+// as a typed one, races the deadline: past it the 504 is sent at once and the handler finishes
+// detached. Others hold c, so they are waited for. Either way a result returned successfully
+// is kept, even if the deadline passed meanwhile.
+func (i *RouteIncome) runHandler(c *fiber.Ctx, f *FluxGo, http *Http, apm *Apm, handler routeHandler, income interface{}) (*GlobalResponse, *GlobalError, error) {
+	ctx := c.UserContext()
+	timeout := i.timeout(http)
+
+	if timeout <= 0 || handler.detached == nil {
+		res, gErr := handler.http(c, income)
+		if gErr != nil {
+			if err := timeoutError(ctx, apm, timeout); err != nil {
+				return nil, err, nil
+			}
+		}
+		return res, gErr, nil
+	}
+
+	files := formFiles(c)
+	done := make(chan handlerResult, 1)
+	go func() {
+		var r handlerResult
+		defer func() {
+			r.recovered = recover()
+			done <- r
+		}()
+		r.res, r.gErr = handler.detached(ctx, income)
+	}()
+
+	var r handlerResult
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		select {
+		case r = <-done:
+		default:
+			if err := timeoutError(ctx, apm, timeout); err != nil {
+				name := c.Method() + " " + c.Path()
+				result := make(chan handlerResult, 1)
+				go func() {
+					r := <-done
+					if r.recovered != nil {
+						f.LogError("HTTP", fmt.Sprintf("Recovered panic after the timeout of %s: %v", name, r.recovered))
+					}
+					closeFiles(files)
+					result <- r
+				}()
+				return nil, nil, &routeTimedOut{sendErr: http.SendError(c, err), result: result}
+			}
+			r = <-done
+		}
+	}
+
+	if r.recovered != nil {
+		panic(r.recovered)
+	}
+
+	return r.res, r.gErr, nil
+}
+
+// timeoutError reports a request whose deadline passed, recording it on the request span.
+func timeoutError(ctx context.Context, apm *Apm, timeout time.Duration) *GlobalError {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+
+	span := apm.GetSpanFromContext(ctx)
+	span.SetAttributes(attribute.Bool("http.timeout", true), attribute.String("http.timeout.duration", timeout.String()))
+	span.SetError(fmt.Errorf("request timed out after %s", timeout))
+
+	return ErrorGatewayTimeout(fmt.Sprintf("Request timed out after %s", timeout))
+}
@@ -0,0 +1,80 @@
+package fluxgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutTestHandler struct{}
+
+func (h *timeoutTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	ctx := c.UserContext()
+	if c.Query("ignore") != "" {
+		time.Sleep(300 * time.Millisecond)
+		return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ErrorInternalError(ctx.Err().Error())
+	case <-time.After(100 * time.Millisecond):
+		return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+	}
+}
+
+type timeoutTestTypedHandler struct{}
+
+func (h *timeoutTestTypedHandler) Handle(ctx context.Context, req *struct{}) (*fiber.Map, *GlobalError) {
+	time.Sleep(300 * time.Millisecond)
+	return &fiber.Map{"ok": true}, nil
+}
+
+func TestRouteTimeout(t *testing.T) {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{RouteTimeout: 20 * time.Millisecond}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *timeoutTestHandler { return &timeoutTestHandler{} }).
+		AddHandler(func() *timeoutTestTypedHandler { return &timeoutTestTypedHandler{} }).
+		Route(
+			GET[timeoutTestHandler]("/api", "/default", RouteIncome{}),
+			GET[timeoutTestHandler]("/api", "/longer", RouteIncome{Timeout: time.Second}),
+			GET[timeoutTestHandler]("/api", "/unlimited", RouteIncome{Timeout: -1}),
+			TypedGET[timeoutTestTypedHandler, struct{}, fiber.Map]("/api", "/typed", RouteIncome{}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should reply 504 and cancel the context once the default timeout passes", func(t *testing.T) {
+		status, body := RunTestRequest(http, "GET", "/api/default", nil, nil)
+		assert.Equal(t, 504, status)
+		assert.Equal(t, "timeout", body["code"])
+	})
+
+	t.Run("Should reply 504 on time when a typed handler ignores its context", func(t *testing.T) {
+		start := time.Now()
+		status, body := RunTestRequest(http, "GET", "/api/typed", nil, nil)
+		assert.Equal(t, 504, status)
+		assert.Equal(t, "timeout", body["code"])
+		assert.Less(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("Should keep a successful result returned past the deadline", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "GET", "/api/default?ignore=true", nil, nil)
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should use the route timeout over the default", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "GET", "/api/longer", nil, nil)
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should not set a deadline when the route timeout is negative", func(t *testing.T) {
+		status, _ := RunTestRequestRaw(http, "GET", "/api/unlimited", nil, nil)
+		assert.Equal(t, 200, status)
+	})
+}
@@ -36,8 +36,13 @@ func ToolsStart(apm *Apm) *Tools {
 }
 
 func (f *FluxGo) AddTools() *FluxGo {
+	f.markProvided(DependencyTools)
+
 	f.AddDependency(func(apm *Apm) *Tools {
-		return ToolsStart(apm)
+		tools := ToolsStart(apm)
+		f.sources.tools = tools
+
+		return tools
 	})
 
 	return f
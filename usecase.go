@@ -0,0 +1,209 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UseCase is transport-agnostic logic bound to HTTP, Kafka, tools, cron and gRPC
+// by UseCaseHttp, UseCaseTopic, UseCaseTool, UseCaseCron and ExecuteGrpc.
+type UseCase[Req any, Res any] interface {
+	Execute(ctx context.Context, req *Req) (*Res, *GlobalError)
+}
+
+type useCaseHandler[Req any, Res any] struct {
+	useCase UseCase[Req, Res]
+}
+
+func (h useCaseHandler[Req, Res]) Handle(ctx context.Context, req *Req) (*Res, *GlobalError) {
+	return h.useCase.Execute(ctx, req)
+}
+
+// UseCaseHttp exposes use case *T as an HTTP route, like TypedHttpDef.
+//
+// Usage: UseCaseHttp[GetUser, dto.GetUserReq, dto.GetUserRes](group, "GET", path, config)
+func UseCaseHttp[T any, Req any, Res any, PT interface {
+	*T
+	UseCase[Req, Res]
+}](group, method, path string, config RouteIncome) RouteDefinition {
+	return typedHttpDef(group, method, path, config, func(handler PT) TypedHttpHandler[Req, Res] {
+		return useCaseHandler[Req, Res]{handler}
+	})
+}
+
+// UseCaseTopic consumes topic with use case *T, decoding each message as JSON into Req
+// and validating it. Messages that cannot be decoded or validated, and 4xx errors, are
+// acknowledged since retrying cannot succeed, and always logged so none is lost without a
+// trace; other errors leave them unacknowledged.
+func UseCaseTopic[T any, Req any, Res any, PT interface {
+	*T
+	UseCase[Req, Res]
+}](topic string) RouteDefinition {
+	return &topicRouteDef{
+		makeFn: func(m *FluxModule) interface{} {
+			return func(f *FluxGo, kafka *Kafka, handler PT) error {
+				return kafka.addConsumer(Consumer{
+					topic:       topic,
+					module:      m.Name,
+					handlerType: handlerTypeName[T](),
+					handler: func(ctx context.Context, data []byte) error {
+						req := new(Req)
+						if err := json.Unmarshal(data, req); err != nil {
+							f.LogError("KAFKA", fmt.Sprintf("Discarding message on %s: %v", topic, err))
+							return nil
+						}
+						if hasErrors, gErr := f.Validator().RunContext(ctx, req, ""); hasErrors {
+							f.LogError("KAFKA", fmt.Sprintf("Discarding invalid message on %s: %v", topic, gErr.Errors))
+							return nil
+						}
+
+						_, gErr := handler.Execute(ctx, req)
+						return topicError(f, topic, gErr)
+					},
+				})
+			}
+		},
+	}
+}
+
+// topicError maps a use case error to the consumer result.
+func topicError(f *FluxGo, topic string, err *GlobalError) error {
+	if err == nil {
+		return nil
+	}
+	if err.Status >= 400 && err.Status < 500 {
+		f.LogError("KAFKA", fmt.Sprintf("Discarding message on %s: %s", topic, useCaseError{err}.Error()))
+		return nil
+	}
+
+	return useCaseError{err}
+}
+
+// useCaseTool adapts a use case to ToolsInterface.
+type useCaseTool[Req any, Res any] struct {
+	name        string
+	description string
+	useCase     UseCase[Req, Res]
+	validator   *Validator
+}
+
+func (t *useCaseTool[Req, Res]) Name() string        { return t.name }
+func (t *useCaseTool[Req, Res]) Description() string { return t.description }
+func (t *useCaseTool[Req, Res]) Schema() ToolsSchema { return ToolParseSchema(*new(Req)) }
+func (t *useCaseTool[Req, Res]) ExecuteTool(ctx context.Context, raw json.RawMessage) (json.RawMessage, error) {
+	req := new(Req)
+	if err := json.Unmarshal(raw, req); err != nil {
+		return nil, useCaseError{ErrorBadRequest(err.Error(), "error.parse")}
+	}
+	if hasErrors, gErr := t.validator.RunContext(ctx, req, ""); hasErrors {
+		return nil, useCaseError{gErr}
+	}
+
+	res, gErr := t.useCase.Execute(ctx, req)
+	if gErr != nil {
+		return nil, useCaseError{gErr}
+	}
+
+	return json.Marshal(res)
+}
+
+// UseCaseTool registers use case *T as a tool whose schema is generated from Req.
+func UseCaseTool[T any, Req any, Res any, PT interface {
+	*T
+	UseCase[Req, Res]
+}](name, description string) RouteDefinition {
+	return &toolRouteDef{
+		makeFn: func(m *FluxModule) interface{} {
+			return func(f *FluxGo, tools *Tools, handler PT) error {
+				return m.ToolRoute(f, tools, &useCaseTool[Req, Res]{
+					name:        name,
+					description: description,
+					useCase:     handler,
+					validator:   f.Validator(),
+				})
+			}
+		},
+	}
+}
+
+// UseCaseCron runs use case *T on crontab with a zero Req.
+func UseCaseCron[T any, Req any, Res any, PT interface {
+	*T
+	UseCase[Req, Res]
+}](crontab string) RouteDefinition {
+	return &cronRouteDef{
+		makeFn: func(m *FluxModule) interface{} {
+			return func(cron *Cron, handler PT) error {
+				return m.CronRouteNamed(cron, cronJobName[T](m), crontab, func(ctx context.Context) error {
+					if _, err := handler.Execute(ctx, new(Req)); err != nil {
+						return useCaseError{err}
+					}
+					return nil
+				})
+			}
+		},
+	}
+}
+
+// ExecuteGrpc runs a use case from a gRPC method, returning its GlobalError as a gRPC status.
+func ExecuteGrpc[Req any, Res any](ctx context.Context, useCase UseCase[Req, Res], req *Req) (*Res, error) {
+	res, err := useCase.Execute(ctx, req)
+	if err != nil {
+		return nil, useCaseError{err}.GRPCStatus().Err()
+	}
+
+	return res, nil
+}
+
+// useCaseError adapts a non-nil *GlobalError to error for the transports expecting one.
+// *GlobalError is not an error itself, so a nil one never becomes a non-nil error.
+type useCaseError struct {
+	*GlobalError
+}
+
+func (e useCaseError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return e.Code
+}
+
+// GRPCStatus maps the HTTP status of the error to a gRPC status.
+func (e useCaseError) GRPCStatus() *status.Status {
+	return status.New(grpcCode(e.Status), e.Error())
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
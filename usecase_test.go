@@ -0,0 +1,104 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type useCaseTestReq struct {
+	Id string `json:"id" params:"id" validate:"required"`
+}
+type useCaseTestRes struct {
+	Id string `json:"id"`
+}
+
+type useCaseTestGetUser struct {
+	calls []string
+}
+
+func (u *useCaseTestGetUser) Execute(ctx context.Context, req *useCaseTestReq) (*useCaseTestRes, *GlobalError) {
+	u.calls = append(u.calls, req.Id)
+
+	switch req.Id {
+	case "missing":
+		return nil, ErrorNotFound("User not found")
+	case "broken":
+		return nil, ErrorInternalError("Database unavailable")
+	}
+	return &useCaseTestRes{Id: req.Id}, nil
+}
+
+func TestUseCase(t *testing.T) {
+	ctx := context.Background()
+	flux := New(FluxGoConfig{Name: "Test"})
+	module := Module("user")
+	useCase := &useCaseTestGetUser{}
+
+	t.Run("Should expose the use case over HTTP", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(HttpConfigData) {})
+		flux.AddModule(Module("user").
+			AddHandler(func() *useCaseTestGetUser { return &useCaseTestGetUser{} }).
+			Route(UseCaseHttp[useCaseTestGetUser, useCaseTestReq, useCaseTestRes]("", "GET", "/users/:id", RouteIncome{FromParam: true, Validate: true})))
+
+		_, http := flux.GetTestApp(t)
+
+		status, body := RunTestRequestRaw(http, "GET", "/users/1", nil, nil)
+		assert.Equal(t, 200, status)
+		assert.JSONEq(t, `{"id": "1"}`, string(body))
+
+		status, _ = RunTestRequestRaw(http, "GET", "/users/missing", nil, nil)
+		assert.Equal(t, 404, status)
+	})
+
+	t.Run("Should consume topic messages and map errors", func(t *testing.T) {
+		kafka := &Kafka{}
+		def := UseCaseTopic[useCaseTestGetUser, useCaseTestReq, useCaseTestRes]("users").(*topicRouteDef)
+		assert.NoError(t, def.makeFn(module).(func(*FluxGo, *Kafka, *useCaseTestGetUser) error)(flux, kafka, useCase))
+
+		consumer := kafka.consumers[0]
+		assert.Equal(t, "users", consumer.topic)
+		assert.Equal(t, "useCaseTestGetUser", consumer.handlerType)
+
+		assert.NoError(t, consumer.handler(ctx, []byte(`{"id": "1"}`)))
+		assert.NoError(t, consumer.handler(ctx, []byte(`not json`)))
+		assert.NoError(t, consumer.handler(ctx, []byte(`{}`)))
+		assert.NoError(t, consumer.handler(ctx, []byte(`{"id": "missing"}`)))
+		assert.EqualError(t, consumer.handler(ctx, []byte(`{"id": "broken"}`)), "Database unavailable")
+	})
+
+	t.Run("Should expose the use case as a tool", func(t *testing.T) {
+		tools := ToolsStart(nil)
+		def := UseCaseTool[useCaseTestGetUser, useCaseTestReq, useCaseTestRes]("get_user", "Get a user").(*toolRouteDef)
+		assert.NoError(t, def.makeFn(module).(func(*FluxGo, *Tools, *useCaseTestGetUser) error)(flux, tools, useCase))
+
+		tool := tools.GetTool("get_user")
+		assert.Equal(t, "Get a user", tool.Description())
+		_, hasId := tool.Schema().Properties.Get("id")
+		assert.True(t, hasId)
+
+		res, err := tool.ExecuteTool(ctx, json.RawMessage(`{"id": "1"}`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"id": "1"}`, string(res))
+
+		_, err = tool.ExecuteTool(ctx, json.RawMessage(`{}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Should map GlobalError to gRPC status", func(t *testing.T) {
+		_, err := ExecuteGrpc[useCaseTestReq, useCaseTestRes](ctx, useCase, &useCaseTestReq{Id: "missing"})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, codes.Unauthenticated, status.Code(useCaseError{&GlobalError{Status: 401}}))
+		assert.Equal(t, codes.Internal, status.Code(useCaseError{ErrorInternalError("boom")}))
+
+		_, isError := any((*GlobalError)(nil)).(error)
+		assert.False(t, isError)
+	})
+}
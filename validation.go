@@ -0,0 +1,279 @@
+package fluxgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/pt"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
+	pt_BR_translations "github.com/go-playground/validator/v10/translations/pt_BR"
+)
+
+// validationLocales are the languages with translated messages; the first is the fallback.
+var validationLocales = []struct {
+	locale   locales.Translator
+	register func(v *validator.Validate, trans ut.Translator) error
+}{
+	{en.New(), en_translations.RegisterDefaultTranslations},
+	{es.New(), es_translations.RegisterDefaultTranslations},
+	{pt.New(), pt_translations.RegisterDefaultTranslations},
+	{pt_BR.New(), pt_BR_translations.RegisterDefaultTranslations},
+	{fr.New(), fr_translations.RegisterDefaultTranslations},
+	{de.New(), de_translations.RegisterDefaultTranslations},
+}
+
+// Validator validates the entities of routes and use cases. Custom tags are added with AddRule
+// and struct-level rules with RegisterStructValidation, reporting fields by their JSON name.
+// Errors name fields by their path in the JSON, query, params, header or form tag namespace,
+// e.g. "address.zip", with messages in English, Spanish, Portuguese, French or German.
+type Validator struct {
+	*validator.Validate
+	translator *ut.UniversalTranslator
+}
+
+// CrossValidator is implemented by entities with rules across their fields, run once every
+// request source is merged and the tags passed, e.g. a body field matching a path param.
+// report adds an error on field for tag, translated like the tags of AddRule.
+type CrossValidator interface {
+	CrossValidate(ctx context.Context, report func(field, tag, param string))
+}
+
+// ValidationError is a failed rule of a validation error response.
+type ValidationError struct {
+	FailedField string      `json:"failed_field"`
+	Tag         string      `json:"tag"`
+	Param       string      `json:"param,omitempty"`
+	Value       interface{} `json:"value"`
+	Message     string      `json:"message"`
+}
+
+func newValidator() *Validator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(fieldTagName)
+
+	supported := make([]locales.Translator, 0, len(validationLocales))
+	for _, l := range validationLocales {
+		supported = append(supported, l.locale)
+	}
+	uni := ut.New(supported[0], supported...)
+
+	for _, l := range validationLocales {
+		trans, _ := uni.GetTranslator(l.locale.Locale())
+		if err := l.register(validate, trans); err != nil {
+			panic(fmt.Errorf("failed to register %s validation messages: %w", l.locale.Locale(), err))
+		}
+	}
+
+	return &Validator{validate, uni}
+}
+
+// fieldTagName names a field after the tag it is read from, so errors match the request.
+func fieldTagName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query", "params", "reqHeader", "header", "form"} {
+		if name := tagValue(field, key); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// AddRule registers the validation tag with its messages by locale, see RegisterMessage.
+func (v *Validator) AddRule(tag string, fn validator.Func, messages map[string]string) error {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+
+	for locale, message := range messages {
+		if err := v.RegisterMessage(locale, tag, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterMessage sets the message of tag in locale, e.g. "en" or "pt_BR", replacing {0} with
+// the field and {1} with the tag parameter.
+func (v *Validator) RegisterMessage(locale, tag, message string) error {
+	trans, found := v.translator.GetTranslator(locale)
+	if !found {
+		return fmt.Errorf("unsupported validation locale: %s", locale)
+	}
+
+	return v.RegisterTranslation(tag, trans, func(t ut.Translator) error {
+		return t.Add(tag, message, true)
+	}, func(t ut.Translator, fe validator.FieldError) string {
+		return translateRule(t, fe.Tag(), fe.Field(), fe.Param())
+	})
+}
+
+// Translator returns the translator of the preferred language of acceptLanguage with messages,
+// by q weight, falling back from regional variants to the base language and then to English.
+func (v *Validator) Translator(acceptLanguage string) ut.Translator {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	languages := []language{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		languages = append(languages, language{strings.ReplaceAll(tag, "-", "_"), quality})
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+
+	candidates := []string{}
+	for _, l := range languages {
+		candidates = append(candidates, l.tag)
+		if base, _, regional := strings.Cut(l.tag, "_"); regional {
+			candidates = append(candidates, base)
+		}
+	}
+
+	trans, _ := v.translator.FindTranslator(candidates...)
+
+	return trans
+}
+
+func (v *Validator) Run(data interface{}) (bool, *GlobalError) {
+	return v.RunContext(context.Background(), data, "")
+}
+
+// RunContext validates the tags of data and then its CrossValidator rules, with messages in
+// the language of acceptLanguage.
+func (v *Validator) RunContext(ctx context.Context, data interface{}, acceptLanguage string) (bool, *GlobalError) {
+	trans := v.Translator(acceptLanguage)
+
+	validationErrors := []ValidationError{}
+
+	var fieldErrors validator.ValidationErrors
+	if errs := v.StructCtx(ctx, data); errors.As(errs, &fieldErrors) {
+		for _, err := range fieldErrors {
+			field := fieldPath(reflect.TypeOf(data), err)
+
+			// Tags without a message translate to the raw error.
+			message := err.Translate(trans)
+			if message == err.Error() {
+				message = translateRule(trans, err.Tag(), field, err.Param())
+			}
+
+			validationErrors = append(validationErrors, ValidationError{
+				FailedField: field,
+				Tag:         err.Tag(),
+				Param:       err.Param(),
+				Value:       err.Value(),
+				Message:     message,
+			})
+		}
+	}
+
+	if cross, ok := data.(CrossValidator); ok && len(validationErrors) == 0 {
+		cross.CrossValidate(ctx, func(field, tag, param string) {
+			validationErrors = append(validationErrors, ValidationError{
+				FailedField: field,
+				Tag:         tag,
+				Param:       param,
+				Message:     translateRule(trans, tag, field, param),
+			})
+		})
+	}
+
+	hasError := len(validationErrors) > 0
+
+	validationErr := &GlobalError{
+		Code:    "error.validation",
+		Success: false,
+		Errors:  validationErrors,
+		Status:  400,
+	}
+
+	return hasError, validationErr
+}
+
+// translateRule returns the message of tag, or a generic one when it has no translation.
+func translateRule(trans ut.Translator, tag, field, param string) string {
+	message, err := trans.T(tag, field, param)
+	if err != nil {
+		return fmt.Sprintf("%s failed on the %s rule", field, tag)
+	}
+
+	return message
+}
+
+// fieldPath returns the path of a failed field under root in the tag namespace, e.g.
+// "address.zip" or "items[0].sku", leaving out embedded structs.
+func fieldPath(root reflect.Type, err validator.FieldError) string {
+	segments := strings.Split(err.StructNamespace(), ".")[1:]
+
+	t := root
+	path := []string{}
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return strings.TrimPrefix(err.Namespace(), strings.Split(err.Namespace(), ".")[0]+".")
+		}
+
+		field, found := t.FieldByName(name)
+		if !found {
+			// Struct-level validators may report fields that do not exist.
+			path = append(path, segment)
+			continue
+		}
+
+		t = field.Type
+		for range strings.Count(index, "[") {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+
+		if field.Anonymous && index == "" {
+			continue
+		}
+		if tagName := fieldTagName(field); tagName != "" {
+			name = tagName
+		}
+		path = append(path, name+index)
+	}
+
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,136 @@
+package fluxgo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type validationTestAddress struct {
+	Zip string `json:"zip" validate:"required"`
+}
+
+type validationTestItem struct {
+	Sku string `json:"sku" validate:"required"`
+}
+
+type validationTestOrder struct {
+	PageRequest
+	Address validationTestAddress `json:"address"`
+	Items   []validationTestItem  `json:"items" validate:"dive"`
+}
+
+type validationTestProduct struct {
+	Code string `json:"code" validate:"sku_code"`
+}
+
+type validationTestUpdate struct {
+	ID     string `params:"id" json:"-"`
+	BodyID string `json:"id" validate:"required"`
+	Name   string `json:"name"`
+}
+
+func (u *validationTestUpdate) CrossValidate(ctx context.Context, report func(field, tag, param string)) {
+	if u.BodyID != u.ID {
+		report("id", "eqfield", "params.id")
+	}
+}
+
+type validationTestHandler struct{}
+
+func (h *validationTestHandler) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"ok": true}}, nil
+}
+
+func validationTestErrors(t *testing.T, err *GlobalError) map[string]ValidationError {
+	errors := map[string]ValidationError{}
+	for _, e := range err.Errors.([]ValidationError) {
+		errors[e.FailedField] = e
+	}
+	return errors
+}
+
+func TestValidator(t *testing.T) {
+	v := newValidator()
+
+	t.Run("Should report nested fields by their JSON path", func(t *testing.T) {
+		hasErrors, err := v.Run(&validationTestOrder{Items: []validationTestItem{{Sku: "a"}, {}}})
+		assert.True(t, hasErrors)
+
+		errors := validationTestErrors(t, err)
+		assert.Contains(t, errors, "address.zip")
+		assert.Contains(t, errors, "items[1].sku")
+		assert.Equal(t, "zip is a required field", errors["address.zip"].Message)
+	})
+
+	t.Run("Should translate messages per Accept-Language", func(t *testing.T) {
+		_, err := v.RunContext(context.Background(), &validationTestAddress{}, "fr-CA;q=0.9, pt-BR")
+		assert.Equal(t, "zip é um campo obrigatório", validationTestErrors(t, err)["zip"].Message)
+
+		_, err = v.RunContext(context.Background(), &validationTestAddress{}, "es-AR")
+		assert.Equal(t, "zip es un campo requerido", validationTestErrors(t, err)["zip"].Message)
+
+		_, err = v.RunContext(context.Background(), &validationTestAddress{}, "ja")
+		assert.Equal(t, "zip is a required field", validationTestErrors(t, err)["zip"].Message)
+	})
+
+	t.Run("Should register custom rules with messages", func(t *testing.T) {
+		assert.NoError(t, v.AddRule("sku_code", func(fl validator.FieldLevel) bool {
+			return strings.HasPrefix(fl.Field().String(), "SKU-")
+		}, map[string]string{"en": "{0} must be a SKU code", "pt_BR": "{0} deve ser um código SKU"}))
+
+		product := &validationTestProduct{Code: "42"}
+		_, err := v.RunContext(context.Background(), product, "pt-BR")
+		assert.Equal(t, "code deve ser um código SKU", validationTestErrors(t, err)["code"].Message)
+
+		product.Code = "SKU-42"
+		hasErrors, _ := v.Run(product)
+		assert.False(t, hasErrors)
+
+		assert.Error(t, v.RegisterMessage("xx", "sku_code", "{0}"))
+	})
+
+	t.Run("Should report struct-level rules", func(t *testing.T) {
+		v := newValidator()
+		v.RegisterStructValidation(func(sl validator.StructLevel) {
+			if address := sl.Current().Interface().(validationTestAddress); address.Zip == "00000" {
+				sl.ReportError(address.Zip, "zip", "Zip", "zip_exists", "")
+			}
+		}, validationTestAddress{})
+
+		hasErrors, err := v.Run(&validationTestOrder{Address: validationTestAddress{Zip: "00000"}})
+		assert.True(t, hasErrors)
+		errors := validationTestErrors(t, err)
+		assert.Equal(t, "zip_exists", errors["address.zip"].Tag)
+		assert.Equal(t, "address.zip failed on the zip_exists rule", errors["address.zip"].Message)
+	})
+}
+
+func TestCrossValidation(t *testing.T) {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *validationTestHandler { return &validationTestHandler{} }).
+		Route(
+			PUT[validationTestHandler]("/api", "/users/:id", RouteIncome{Entity: validationTestUpdate{}, FromBody: true, FromParam: true, Validate: true}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	t.Run("Should validate across the merged request sources", func(t *testing.T) {
+		status, _ := RunTestRequest(http, "PUT", "/api/users/1", map[string]string{"id": "1"}, nil)
+		assert.Equal(t, 200, status)
+
+		status, body := RunTestRequest(http, "PUT", "/api/users/1", map[string]string{"id": "2"}, &Headers{"Accept-Language": "pt-BR"})
+		assert.Equal(t, 400, status)
+		errors := body["errors"].([]any)
+		assert.Len(t, errors, 1)
+		assert.Equal(t, "id", errors[0].(map[string]any)["failed_field"])
+		assert.Equal(t, "id deve ser igual a params.id", errors[0].(map[string]any)["message"])
+	})
+}
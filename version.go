@@ -0,0 +1,219 @@
+package fluxgo
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VersionStrategy is where the API version of a request is read from.
+type VersionStrategy string
+
+const (
+	// VersionByHeader reads the version from a header such as "Api-Version: 2".
+	VersionByHeader VersionStrategy = "header"
+	// VersionByPath prefixes the route path with the version, e.g. /api/v2/users.
+	VersionByPath VersionStrategy = "path"
+	// VersionByMediaType reads the version from the Accept header, either as a parameter
+	// ("application/json; version=2") or a vendor type ("application/vnd.acme.v2+json").
+	VersionByMediaType VersionStrategy = "media_type"
+
+	ApiVersionContextKey contextKey = "api_version"
+
+	versionPathParam = "apiVersion"
+)
+
+var mediaTypeVersionRe = regexp.MustCompile(`\.v([\w.-]+)\+`)
+
+// VersioningOptions lets routes declare RouteIncome.Version. Routes without a version
+// answer every version.
+type VersioningOptions struct {
+	// Strategy default: VersionByHeader
+	Strategy VersionStrategy
+	// Header read by VersionByHeader. Default: "Api-Version"
+	Header string
+	// Default is the version of requests without one; when empty they are rejected.
+	Default string
+	// Versions lists the supported versions. Each one gets its own OpenAPI document and
+	// routes may only declare these versions. Empty accepts any version.
+	Versions []ApiVersion
+}
+
+// ApiVersion is a supported version and its retirement dates, sent in the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers of its responses.
+type ApiVersion struct {
+	Name       string
+	Deprecated time.Time
+	Sunset     time.Time
+	// Link documents the deprecation, e.g. a migration guide.
+	Link string
+}
+
+// versionedRoute dispatches a method and path to the handler of the requested version.
+type versionedRoute struct {
+	handlers map[string]fiber.Handler
+}
+
+func (v *VersioningOptions) header() string {
+	return defaultString(v.Header, "Api-Version")
+}
+
+func (v *VersioningOptions) version(name string) (ApiVersion, bool) {
+	for _, version := range v.Versions {
+		if version.Name == name {
+			return version, true
+		}
+	}
+
+	return ApiVersion{}, len(v.Versions) == 0
+}
+
+// routePath returns the path of a route of version under group.
+func (v *VersioningOptions) routePath(path, version string) string {
+	if v.Strategy == VersionByPath {
+		return "/v" + version + path
+	}
+
+	return path
+}
+
+// requestVersion reads the version of the request; ok is false when a VersionByPath
+// segment is not a declared version, so the request belongs to another route.
+func (v *VersioningOptions) requestVersion(c *fiber.Ctx, route *versionedRoute) (version string, ok bool) {
+	switch v.Strategy {
+	case VersionByPath:
+		version, ok = strings.CutPrefix(c.Params(versionPathParam), "v")
+		if !ok {
+			return "", false
+		}
+		// Without a Versions list only the versions of the route itself are declared.
+		if len(v.Versions) == 0 {
+			_, ok = route.handlers[version]
+			return version, ok
+		}
+		_, ok = v.version(version)
+		return version, ok
+	case VersionByMediaType:
+		for _, accept := range strings.Split(c.Get(fiber.HeaderAccept), ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+			if err != nil {
+				continue
+			}
+			if version := params["version"]; version != "" {
+				return version, true
+			}
+			if match := mediaTypeVersionRe.FindStringSubmatch(mediaType); match != nil {
+				return match[1], true
+			}
+		}
+	default:
+		if version := c.Get(v.header()); version != "" {
+			return version, true
+		}
+	}
+
+	return v.Default, true
+}
+
+// setDeprecationHeaders announces the retirement of version on the response.
+func (v *VersioningOptions) setDeprecationHeaders(c *fiber.Ctx, name string) {
+	version, _ := v.version(name)
+
+	if !version.Deprecated.IsZero() {
+		c.Set("Deprecation", "@"+strconv.FormatInt(version.Deprecated.Unix(), 10))
+		if version.Link != "" {
+			c.Append(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="deprecation"`, version.Link))
+		}
+	}
+	if !version.Sunset.IsZero() {
+		c.Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// addVersionedRoute registers handler for version, sharing one fiber route between the
+// versions of the same method and path.
+func (h *Http) addVersionedRoute(group, method, path, version string, handler fiber.Handler) error {
+	if h.versioning == nil {
+		return fmt.Errorf("route %s %s%s: RouteIncome.Version requires HttpOptions.Versioning", method, group, path)
+	}
+	if _, declared := h.versioning.version(version); !declared {
+		return fmt.Errorf("route %s %s%s: version %q is not in HttpOptions.Versioning.Versions", method, group, path, version)
+	}
+
+	key := method + " " + group + path
+	if route, exists := h.versionedRoutes[key]; exists {
+		if _, duplicated := route.handlers[version]; duplicated {
+			return fmt.Errorf("route %s %s%s: version %q already registered", method, group, path, version)
+		}
+		route.handlers[version] = handler
+		return nil
+	}
+
+	route := &versionedRoute{handlers: map[string]fiber.Handler{version: handler}}
+	if h.versionedRoutes == nil {
+		h.versionedRoutes = map[string]*versionedRoute{}
+	}
+	h.versionedRoutes[key] = route
+
+	routePath := path
+	if h.versioning.Strategy == VersionByPath {
+		routePath = "/:" + versionPathParam + path
+	}
+	h.addRoute(group, method, routePath, h.dispatchVersion(route))
+
+	return nil
+}
+
+func (h *Http) dispatchVersion(route *versionedRoute) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		version, ok := h.versioning.requestVersion(c, route)
+		if !ok {
+			return c.Next()
+		}
+
+		handler, exists := route.handlers[version]
+		if !exists {
+			return h.SendError(c, errorUnsupportedVersion(h.versioning.Strategy, version))
+		}
+
+		h.versioning.setDeprecationHeaders(c, version)
+		c.SetUserContext(context.WithValue(c.UserContext(), ApiVersionContextKey, version))
+
+		return handler(c)
+	}
+}
+
+func errorUnsupportedVersion(strategy VersionStrategy, version string) *GlobalError {
+	status := fiber.StatusBadRequest
+	if strategy == VersionByMediaType {
+		status = fiber.StatusNotAcceptable
+	}
+
+	message := fmt.Sprintf("Unsupported API version: %s", version)
+	if version == "" {
+		message = "Missing API version"
+	}
+
+	return &GlobalError{
+		Message: message,
+		Code:    "error.unsupported_version",
+		Status:  status,
+		Success: false,
+	}
+}
+
+// addRoute registers handler on the router of group, or on the app when group has no router.
+func (h *Http) addRoute(group, method, path string, handler fiber.Handler) {
+	if r := h.GetRouter(group); r == nil {
+		h.app.Add(method, group+path, handler)
+	} else {
+		(*r).Add(method, path, handler)
+	}
+}
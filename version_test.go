@@ -0,0 +1,161 @@
+package fluxgo
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type versionTestHandlerV1 struct{}
+
+func (h *versionTestHandlerV1) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"version": 1, "context": c.UserContext().Value(ApiVersionContextKey)}}, nil
+}
+
+type versionTestHandlerV2 struct{}
+
+func (h *versionTestHandlerV2) HandleHttp(c *fiber.Ctx, income interface{}) (*GlobalResponse, *GlobalError) {
+	return &GlobalResponse{Status: 200, Content: fiber.Map{"version": 2}}, nil
+}
+
+func newVersionTestApp(t *testing.T, versioning VersioningOptions) *Http {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Versioning: &versioning, Swagger: &SwaggerOptions{}}, func(h HttpConfigData) { h.CreateRouter("/api") })
+	flux.AddModule(Module("test").
+		AddHandler(func() *versionTestHandlerV1 { return &versionTestHandlerV1{} }).
+		AddHandler(func() *versionTestHandlerV2 { return &versionTestHandlerV2{} }).
+		Route(
+			GET[versionTestHandlerV1]("/api", "/users", RouteIncome{Version: "1"}),
+			GET[versionTestHandlerV2]("/api", "/users", RouteIncome{Version: "2"}),
+			GET[versionTestHandlerV2]("/api", "/health", RouteIncome{}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	return http
+}
+
+func TestVersioning(t *testing.T) {
+	deprecated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	versions := []ApiVersion{{Name: "1", Deprecated: deprecated, Sunset: sunset, Link: "https://example.com/v2"}, {Name: "2"}}
+
+	request := func(http *Http, path string, headers map[string]string) (int, fiber.Map, map[string]string) {
+		req := httptest.NewRequest("GET", path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		res, err := http.GetApp().Test(req)
+		assert.NoError(t, err)
+
+		body := fiber.Map{}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return res.StatusCode, body, map[string]string{
+			"Deprecation": res.Header.Get("Deprecation"),
+			"Sunset":      res.Header.Get("Sunset"),
+			"Link":        res.Header.Get("Link"),
+		}
+	}
+
+	t.Run("Should dispatch by header with deprecation headers", func(t *testing.T) {
+		http := newVersionTestApp(t, VersioningOptions{Versions: versions})
+
+		status, body, headers := request(http, "/api/users", map[string]string{"Api-Version": "1"})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(1), body["version"])
+		assert.Equal(t, "1", body["context"])
+		assert.Equal(t, "@1767225600", headers["Deprecation"])
+		assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", headers["Sunset"])
+		assert.Equal(t, `<https://example.com/v2>; rel="deprecation"`, headers["Link"])
+
+		status, body, headers = request(http, "/api/users", map[string]string{"Api-Version": "2"})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(2), body["version"])
+		assert.Empty(t, headers["Deprecation"])
+
+		status, body, _ = request(http, "/api/users", map[string]string{"Api-Version": "3"})
+		assert.Equal(t, 400, status)
+		assert.Equal(t, "error.unsupported_version", body["code"])
+
+		status, _, _ = request(http, "/api/users", nil)
+		assert.Equal(t, 400, status)
+
+		status, _, _ = request(http, "/api/health", map[string]string{"Api-Version": "3"})
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("Should use the default version for requests without one", func(t *testing.T) {
+		http := newVersionTestApp(t, VersioningOptions{Versions: versions, Default: "2"})
+
+		status, body, _ := request(http, "/api/users", nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(2), body["version"])
+	})
+
+	t.Run("Should dispatch by path prefix", func(t *testing.T) {
+		http := newVersionTestApp(t, VersioningOptions{Strategy: VersionByPath, Versions: versions})
+
+		status, body, _ := request(http, "/api/v1/users", nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(1), body["version"])
+
+		status, body, _ = request(http, "/api/v2/users", nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(2), body["version"])
+
+		status, _, _ = request(http, "/api/v3/users", nil)
+		assert.Equal(t, 404, status)
+
+		status, _, _ = request(http, "/api/vip/users", nil)
+		assert.Equal(t, 404, status)
+	})
+
+	t.Run("Should dispatch by media type and reply 406 for unsupported versions", func(t *testing.T) {
+		http := newVersionTestApp(t, VersioningOptions{Strategy: VersionByMediaType, Versions: versions})
+
+		status, body, _ := request(http, "/api/users", map[string]string{"Accept": "application/vnd.acme.v2+json"})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(2), body["version"])
+
+		status, body, _ = request(http, "/api/users", map[string]string{"Accept": "application/json; version=1"})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, float64(1), body["version"])
+
+		status, _, _ = request(http, "/api/users", map[string]string{"Accept": "application/json; version=9"})
+		assert.Equal(t, 406, status)
+	})
+
+	t.Run("Should generate one OpenAPI document per version", func(t *testing.T) {
+		http := newVersionTestApp(t, VersioningOptions{Strategy: VersionByPath, Versions: versions})
+
+		status, body, _ := request(http, "/api/swagger/v1/openapi.json", nil)
+		assert.Equal(t, 200, status)
+		paths := body["paths"].(map[string]interface{})
+		assert.Contains(t, paths, "/api/v1/users")
+		assert.Contains(t, paths, "/api/health")
+		assert.NotContains(t, paths, "/api/v2/users")
+		assert.Equal(t, "1", body["info"].(map[string]interface{})["version"])
+		assert.Equal(t, true, paths["/api/v1/users"].(map[string]interface{})["get"].(map[string]interface{})["deprecated"])
+
+		spec, _ := json.Marshal(http.OpenAPISpec("/api"))
+		assert.Contains(t, string(spec), "/api/v2/users")
+	})
+
+	t.Run("Should reject routes of undeclared versions", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{Versioning: &VersioningOptions{Versions: versions}}, func(h HttpConfigData) {})
+		flux.AddModule(Module("test").
+			AddHandler(func() *versionTestHandlerV1 { return &versionTestHandlerV1{} }).
+			Route(GET[versionTestHandlerV1]("/api", "/users", RouteIncome{Version: "3"})))
+
+		err := fx.New(append(flux.GetFxConfig(), fx.NopLogger)...).Err()
+		assert.ErrorContains(t, err, `version "3" is not in HttpOptions.Versioning.Versions`)
+	})
+}
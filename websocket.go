@@ -0,0 +1,620 @@
+package fluxgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const (
+	defaultWSPingInterval = 30 * time.Second
+	defaultWSWriteTimeout = 10 * time.Second
+
+	wsIncomeLocal  = "fluxgo_ws_income"
+	wsContextLocal = "fluxgo_ws_context"
+)
+
+// ErrWSClosed is returned by WSConn when the connection is closed.
+var ErrWSClosed = errors.New("websocket connection closed")
+
+// WSHandler exchanges messages of type M with a client until it returns or the context
+// is cancelled, which happens when the client disconnects or the application shuts down.
+type WSHandler[M any] interface {
+	HandleWS(ctx context.Context, income interface{}, conn *WSConn[M]) error
+}
+
+type WSConfig struct {
+	// RouteIncome configures the upgrade request; Timeout, Cache and Idempotent are rejected
+	// since they cannot apply to a connection.
+	RouteIncome
+	// PingInterval pings the client, closing the connection when it stops answering. Default: 30s
+	PingInterval time.Duration
+	// WriteTimeout of each message. Default: 10s
+	WriteTimeout time.Duration
+	// ReadLimit is the maximum size in bytes of a received message; larger ones close the connection.
+	// Default: unlimited
+	ReadLimit int64
+	// Origins allowed to connect, from the Origin header, e.g. "https://app.example.com".
+	// "*" allows every origin. Default: the origin of the server itself; requests without
+	// an Origin header, sent by non-browser clients, are always allowed.
+	Origins []string
+}
+
+// WSConn is a WebSocket connection exchanging JSON messages of type M.
+type WSConn[M any] struct {
+	*wsConn
+}
+
+// Read blocks until the next message and decodes it. It returns ErrWSClosed once the
+// connection is closed.
+func (c *WSConn[M]) Read() (M, error) {
+	var message M
+	err := c.ReadJSON(&message)
+
+	return message, err
+}
+
+// Send encodes message as JSON and sends it.
+func (c *WSConn[M]) Send(message M) error {
+	return c.WriteJSON(message)
+}
+
+// wsConn is the untyped side of WSConn, tracked by Http for broadcasts and shutdown.
+type wsConn struct {
+	id           string
+	conn         *fastws.Conn
+	hub          *wsHub
+	writeTimeout time.Duration
+	cancel       context.CancelFunc
+	done         <-chan struct{}
+
+	// writeMu serializes writes, which the handler, broadcasts and pings share.
+	writeMu sync.Mutex
+	// rooms is guarded by hub.mu.
+	rooms map[string]struct{}
+}
+
+// ID identifies the connection, e.g. to keep it in a session store.
+func (c *wsConn) ID() string {
+	return c.id
+}
+
+// ReadJSON decodes the next message into v.
+func (c *wsConn) ReadJSON(v any) error {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		c.cancel()
+		if c.closed() || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+			return ErrWSClosed
+		}
+		return err
+	}
+	c.hub.messagesIn.Add(1)
+
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON encodes v as JSON and sends it.
+func (c *wsConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.write(websocket.TextMessage, data)
+}
+
+// Join adds the connection to room, see Http.Broadcast. It leaves every room when closed.
+func (c *wsConn) Join(room string) {
+	c.hub.join(c, room)
+}
+
+func (c *wsConn) Leave(room string) {
+	c.hub.leave(c, room)
+}
+
+// Rooms returns the rooms the connection joined.
+func (c *wsConn) Rooms() []string {
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+
+	return rooms
+}
+
+// Close closes the connection with code and reason, e.g. websocket.ClosePolicyViolation.
+func (c *wsConn) Close(code int, reason string) error {
+	defer c.cancel()
+
+	return c.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+}
+
+// closed reports whether the connection was cancelled, e.g. on shutdown.
+func (c *wsConn) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *wsConn) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if err := c.conn.WriteMessage(messageType, data); err != nil {
+		c.cancel()
+		return err
+	}
+	c.hub.messagesOut.Add(1)
+
+	return nil
+}
+
+func (c *wsConn) writePrepared(message *fastws.PreparedMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if err := c.conn.WritePreparedMessage(message); err != nil {
+		c.cancel()
+		return err
+	}
+	c.hub.messagesOut.Add(1)
+
+	return nil
+}
+
+func (c *wsConn) writeControl(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.conn.WriteControl(messageType, data, time.Now().Add(c.writeTimeout))
+}
+
+// keepAlive pings the client every interval; a missing pong fails the next read.
+func (c *wsConn) keepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.writeControl(websocket.PingMessage, nil); err != nil {
+				c.cancel()
+				return
+			}
+		}
+	}
+}
+
+// WSMetrics describes the WebSocket connections of an Http.
+type WSMetrics struct {
+	// Connections currently open.
+	Connections int `json:"connections"`
+	// Rooms maps each room to its number of connections.
+	Rooms map[string]int `json:"rooms"`
+	// Accepted counts the connections accepted since start.
+	Accepted    int64 `json:"accepted"`
+	MessagesIn  int64 `json:"messages_in"`
+	MessagesOut int64 `json:"messages_out"`
+}
+
+// wsHub tracks the open connections of an Http and their rooms.
+type wsHub struct {
+	mu     sync.RWMutex
+	conns  map[*wsConn]struct{}
+	rooms  map[string]map[*wsConn]struct{}
+	closed bool
+
+	accepted    atomic.Int64
+	messagesIn  atomic.Int64
+	messagesOut atomic.Int64
+
+	instrumentOnce sync.Once
+	instrumentErr  error
+}
+
+// add tracks c, or reports false once the hub is closed for shutdown.
+func (h *wsHub) add(c *wsConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	if h.conns == nil {
+		h.conns = map[*wsConn]struct{}{}
+	}
+	h.conns[c] = struct{}{}
+	h.accepted.Add(1)
+
+	return true
+}
+
+func (h *wsHub) remove(c *wsConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns, c)
+	for room := range c.rooms {
+		h.removeFromRoom(c, room)
+	}
+}
+
+func (h *wsHub) join(c *wsConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, open := h.conns[c]; !open {
+		return
+	}
+	if h.rooms == nil {
+		h.rooms = map[string]map[*wsConn]struct{}{}
+	}
+	if h.rooms[room] == nil {
+		h.rooms[room] = map[*wsConn]struct{}{}
+	}
+	h.rooms[room][c] = struct{}{}
+	c.rooms[room] = struct{}{}
+}
+
+func (h *wsHub) leave(c *wsConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeFromRoom(c, room)
+}
+
+func (h *wsHub) removeFromRoom(c *wsConn, room string) {
+	delete(c.rooms, room)
+	delete(h.rooms[room], c)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+func (h *wsHub) members(room string) []*wsConn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	members := make([]*wsConn, 0, len(h.rooms[room]))
+	for c := range h.rooms[room] {
+		members = append(members, c)
+	}
+
+	return members
+}
+
+// close rejects new connections and closes the open ones with CloseGoingAway.
+func (h *wsHub) close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*wsConn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.Close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+func (h *wsHub) metrics() WSMetrics {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	rooms := make(map[string]int, len(h.rooms))
+	for room, members := range h.rooms {
+		rooms[room] = len(members)
+	}
+
+	return WSMetrics{
+		Connections: len(h.conns),
+		Rooms:       rooms,
+		Accepted:    h.accepted.Load(),
+		MessagesIn:  h.messagesIn.Load(),
+		MessagesOut: h.messagesOut.Load(),
+	}
+}
+
+// instrument reports the WebSocket metrics through the OpenTelemetry meter of m.
+func (h *wsHub) instrument(m *Metrics) error {
+	h.instrumentOnce.Do(func() {
+		h.instrumentErr = h.registerMetrics(m)
+	})
+
+	return h.instrumentErr
+}
+
+func (h *wsHub) registerMetrics(m *Metrics) error {
+	connections, err := m.meter.Int64ObservableGauge("websocket.connections", metric.WithDescription("Open WebSocket connections"))
+	if err != nil {
+		return err
+	}
+	accepted, err := m.meter.Int64ObservableCounter("websocket.connections.accepted", metric.WithDescription("WebSocket connections accepted"))
+	if err != nil {
+		return err
+	}
+	messages, err := m.meter.Int64ObservableCounter("websocket.messages", metric.WithDescription("WebSocket messages by direction"))
+	if err != nil {
+		return err
+	}
+
+	_, err = m.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		snapshot := h.metrics()
+		o.ObserveInt64(connections, int64(snapshot.Connections))
+		o.ObserveInt64(accepted, snapshot.Accepted)
+		o.ObserveInt64(messages, snapshot.MessagesIn, metric.WithAttributes(attribute.String("direction", "in")))
+		o.ObserveInt64(messages, snapshot.MessagesOut, metric.WithAttributes(attribute.String("direction", "out")))
+		return nil
+	}, connections, accepted, messages)
+
+	return err
+}
+
+// Broadcast sends message as JSON to every connection in room and returns how many
+// received it. Connections failing to receive it are closed.
+func (h *Http) Broadcast(room string, message any) (int, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return 0, err
+	}
+
+	prepared, err := fastws.NewPreparedMessage(websocket.TextMessage, data)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, c := range h.wsHub.members(room) {
+		if err := c.writePrepared(prepared); err == nil {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// WSMetrics returns the current WebSocket connections, rooms and message counts.
+func (h *Http) WSMetrics() WSMetrics {
+	return h.wsHub.metrics()
+}
+
+// wsRouteParams resolves the dependencies of a WebSocket route; Metrics reports its connections when added.
+type wsRouteParams[PT any] struct {
+	fx.In
+
+	Flux    *FluxGo
+	Http    *Http
+	Apm     *Apm
+	Metrics *Metrics `optional:"true"`
+	Handler PT
+}
+
+// WS creates a WebSocket route definition that auto-resolves handler *T from DI.
+// The upgrade request is checked and parsed like other HTTP routes before the connection opens.
+//
+// Usage: WS[ChatRoom, dto.ChatMessage](group, path, config)
+func WS[T any, M any, PT interface {
+	*T
+	WSHandler[M]
+}](group, path string, config WSConfig) RouteDefinition {
+	return &httpRouteDef{
+		group: group, method: fiber.MethodGet, path: path, config: config.RouteIncome,
+		makeFn: func(m *FluxModule) interface{} {
+			return func(p wsRouteParams[PT]) error {
+				if p.Metrics != nil {
+					if err := p.Http.wsHub.instrument(p.Metrics); err != nil {
+						return fmt.Errorf("websocket metrics: %w", err)
+					}
+				}
+
+				return wsRoute[M](m, p.Flux, p.Http, p.Apm, group, path, config, p.Handler)
+			}
+		},
+	}
+}
+
+// wsRoute registers a WebSocket route for handler, like FluxModule.HttpRoute.
+func wsRoute[M any](m *FluxModule, f *FluxGo, http *Http, apm *Apm, group, path string, config WSConfig, handler WSHandler[M]) error {
+	pingInterval := defaultDuration(config.PingInterval, defaultWSPingInterval)
+	writeTimeout := defaultDuration(config.WriteTimeout, defaultWSWriteTimeout)
+	name := fmt.Sprintf("WS %s%s", group, path)
+
+	if err := config.checkStream(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	rateLimitStore, err := config.rateLimitStore(http)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	upgrade := websocket.New(func(conn *websocket.Conn) {
+		parent, _ := conn.Locals(wsContextLocal).(context.Context)
+		if parent == nil {
+			parent = context.Background()
+		}
+		income := conn.Locals(wsIncomeLocal)
+		// conn is released to a pool when this function returns, so goroutines keep the socket.
+		socket := conn.Conn
+
+		// The connection outlives the fiber handler, so it gets its own span linked to the request.
+		ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+		ctx, span := apm.StartSpan(ctx, name, trace.WithLinks(trace.LinkFromContext(parent)))
+		defer span.End()
+		defer cancel()
+
+		c := &wsConn{
+			id:           uuid.NewString(),
+			conn:         socket,
+			hub:          &http.wsHub,
+			writeTimeout: writeTimeout,
+			cancel:       cancel,
+			done:         ctx.Done(),
+			rooms:        map[string]struct{}{},
+		}
+		span.SetAttributes(attribute.String("websocket.connection_id", c.id))
+
+		if !http.wsHub.add(c) {
+			_ = c.Close(websocket.CloseGoingAway, "server shutting down")
+			return
+		}
+		defer http.wsHub.remove(c)
+
+		if config.ReadLimit > 0 {
+			socket.SetReadLimit(config.ReadLimit)
+		}
+		_ = socket.SetReadDeadline(time.Now().Add(pingInterval * 2))
+		socket.SetPongHandler(func(string) error {
+			return socket.SetReadDeadline(time.Now().Add(pingInterval * 2))
+		})
+		go c.keepAlive(ctx, pingInterval)
+
+		// Closing the socket unblocks a pending read once the context is cancelled.
+		go func() {
+			<-ctx.Done()
+			_ = socket.Close()
+		}()
+
+		err := func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic: %v", r)
+				}
+			}()
+			return handler.HandleWS(ctx, income, &WSConn[M]{c})
+		}()
+		if err != nil && !errors.Is(err, ErrWSClosed) && ctx.Err() == nil {
+			span.SetError(err)
+			f.LogError("HTTP", fmt.Sprintf("%s failed: %v", name, err))
+			_ = c.Close(websocket.CloseInternalServerErr, "internal server error")
+			return
+		}
+		_ = c.Close(websocket.CloseNormalClosure, "")
+	}, websocket.Config{Origins: []string{"*"}}) // origins are checked by wsOriginAllowed
+
+	fun := func(c *fiber.Ctx) (resErr error) {
+		defer func() {
+			if r := recover(); r != nil {
+				f.LogError("HTTP", fmt.Sprintf("Recovered panic on %s: %v", name, r))
+				resErr = http.SendError(c, ErrorInternalError("Internal server error"))
+			}
+		}()
+
+		if !websocket.IsWebSocketUpgrade(c) {
+			return http.SendError(c, &GlobalError{
+				Message: "WebSocket upgrade required",
+				Code:    "error.upgrade_required",
+				Status:  fiber.StatusUpgradeRequired,
+				Success: false,
+			})
+		}
+
+		if !wsOriginAllowed(c, config.Origins) {
+			return http.SendError(c, &GlobalError{
+				Message: "Origin not allowed",
+				Code:    "error.forbidden_origin",
+				Status:  fiber.StatusForbidden,
+				Success: false,
+			})
+		}
+
+		if config.RateLimit != nil {
+			if err := config.RateLimit.check(c, f, rateLimitStore, fiber.MethodGet+":"+group+path); err != nil {
+				return http.SendError(c, err)
+			}
+		}
+
+		if err := http.checkPermission(c.UserContext(), config.Permission, nil); err != nil {
+			return http.SendError(c, err)
+		}
+
+		income, err := config.Parse(http, c)
+		if err != nil {
+			return http.SendError(c, err)
+		}
+
+		if config.Permission != nil && config.Permission.Resource != nil {
+			resource, err := config.Permission.Resource(c, income)
+			if err != nil {
+				return http.SendError(c, err)
+			}
+			if err := http.checkPermission(c.UserContext(), config.Permission, resource); err != nil {
+				return http.SendError(c, err)
+			}
+		}
+
+		c.Locals(wsIncomeLocal, income)
+		c.Locals(wsContextLocal, c.UserContext())
+
+		return upgrade(c)
+	}
+
+	docPath, err := config.addRoute(http, group, fiber.MethodGet, path, fun)
+	if err != nil {
+		return err
+	}
+
+	http.addRouteDoc(routeDoc{
+		method:      fiber.MethodGet,
+		path:        docPath,
+		version:     config.Version,
+		module:      m.Name,
+		permission:  config.Permission,
+		rateLimit:   config.RateLimit,
+		tags:        []string{m.swaggerTagName(http)},
+		doc:         config.Doc,
+		entity:      config.Entity,
+		fromQuery:   config.FromQuery,
+		fromParam:   config.FromParam,
+		fromHeader:  config.FromHeader,
+		streamEvent: *new(M),
+		websocket:   true,
+	})
+
+	return nil
+}
+
+// wsOriginAllowed checks the Origin header of an upgrade request against origins, or
+// against the host of the request when origins is empty.
+func wsOriginAllowed(c *fiber.Ctx, origins []string) bool {
+	origin := c.Get(fiber.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+
+	if len(origins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, string(c.Request().Host()))
+	}
+
+	return slices.Contains(origins, "*") || slices.Contains(origins, origin)
+}
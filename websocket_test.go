@@ -0,0 +1,168 @@
+package fluxgo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type wsTestMessage struct {
+	Room string `json:"room,omitempty"`
+	Text string `json:"text"`
+}
+
+type wsTestHandler struct {
+	http *Http
+}
+
+func (h *wsTestHandler) HandleWS(ctx context.Context, income interface{}, conn *WSConn[wsTestMessage]) error {
+	for {
+		message, err := conn.Read()
+		if errors.Is(err, ErrWSClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if message.Room == "" {
+			if err := conn.Send(wsTestMessage{Text: "echo: " + message.Text}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		conn.Join(message.Room)
+		if _, err := h.http.Broadcast(message.Room, wsTestMessage{Room: message.Room, Text: message.Text}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	flux := New(FluxGoConfig{Name: "Test"})
+	flux.AddApm()
+	flux.AddHttp(HttpOptions{Permissions: &Permissions{"member": {{Action: "join", Subject: "chat"}}}}, func(h HttpConfigData) {
+		h.CreateRouter("/api", WithMiddleware(func(c *fiber.Ctx) error {
+			c.SetUserContext(context.WithValue(c.UserContext(), RoleContextKey, c.Get("X-Role")))
+			return c.Next()
+		}))
+	})
+	flux.AddModule(Module("test").
+		AddHandler(func(http *Http) *wsTestHandler { return &wsTestHandler{http: http} }).
+		Route(
+			WS[wsTestHandler, wsTestMessage]("/api", "/chat", WSConfig{RouteIncome: RouteIncome{Permission: &RoutePermission{Action: "join", Subject: "chat"}}}),
+			WS[wsTestHandler, wsTestMessage]("/api", "/limited", WSConfig{RouteIncome: RouteIncome{RateLimit: &RateLimit{Limit: 1, Window: time.Minute}}}),
+		))
+
+	_, http := flux.GetTestApp(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = http.GetApp().Listener(listener) }()
+	t.Cleanup(func() { _ = http.GetApp().Shutdown() })
+
+	url := "ws://" + listener.Addr().String() + "/api/chat"
+	dial := func(t *testing.T) *fastws.Conn {
+		conn, res, err := fastws.DefaultDialer.Dial(url, map[string][]string{"X-Role": {"member"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 101, res.StatusCode)
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+	read := func(t *testing.T, conn *fastws.Conn) wsTestMessage {
+		var message wsTestMessage
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		assert.NoError(t, conn.ReadJSON(&message))
+		return message
+	}
+
+	t.Run("Should exchange typed JSON messages", func(t *testing.T) {
+		conn := dial(t)
+
+		assert.NoError(t, conn.WriteJSON(wsTestMessage{Text: "hello"}))
+		assert.Equal(t, "echo: hello", read(t, conn).Text)
+	})
+
+	t.Run("Should check permissions before upgrading", func(t *testing.T) {
+		_, res, err := fastws.DefaultDialer.Dial(url, nil)
+		assert.ErrorIs(t, err, fastws.ErrBadHandshake)
+		assert.Equal(t, 401, res.StatusCode)
+	})
+
+	t.Run("Should only accept the server origin by default", func(t *testing.T) {
+		headers := map[string][]string{"X-Role": {"member"}, "Origin": {"https://evil.example.com"}}
+		_, res, err := fastws.DefaultDialer.Dial(url, headers)
+		assert.ErrorIs(t, err, fastws.ErrBadHandshake)
+		assert.Equal(t, 403, res.StatusCode)
+
+		headers["Origin"] = []string{"http://" + listener.Addr().String()}
+		conn, res, err := fastws.DefaultDialer.Dial(url, headers)
+		assert.NoError(t, err)
+		assert.Equal(t, 101, res.StatusCode)
+		_ = conn.Close()
+	})
+
+	t.Run("Should rate limit upgrades", func(t *testing.T) {
+		limited := "ws://" + listener.Addr().String() + "/api/limited"
+		conn, _, err := fastws.DefaultDialer.Dial(limited, nil)
+		assert.NoError(t, err)
+		_ = conn.Close()
+
+		_, res, err := fastws.DefaultDialer.Dial(limited, nil)
+		assert.ErrorIs(t, err, fastws.ErrBadHandshake)
+		assert.Equal(t, 429, res.StatusCode)
+	})
+
+	t.Run("Should reject route options a connection cannot honour", func(t *testing.T) {
+		flux := New(FluxGoConfig{Name: "Test"})
+		flux.AddApm()
+		flux.AddHttp(HttpOptions{}, func(HttpConfigData) {})
+		flux.AddModule(Module("test").
+			AddHandler(func(http *Http) *wsTestHandler { return &wsTestHandler{http: http} }).
+			Route(WS[wsTestHandler, wsTestMessage]("", "/chat", WSConfig{RouteIncome: RouteIncome{Timeout: time.Second}})))
+
+		assert.ErrorContains(t, fx.New(flux.GetFxConfig()...).Err(), "not supported on streaming routes")
+	})
+
+	t.Run("Should reject plain HTTP requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/chat", nil)
+		req.Header.Set("X-Role", "member")
+		res, err := http.GetApp().Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 426, res.StatusCode)
+	})
+
+	t.Run("Should broadcast to the connections of a room", func(t *testing.T) {
+		first, second := dial(t), dial(t)
+
+		assert.NoError(t, first.WriteJSON(wsTestMessage{Room: "general", Text: "joined"}))
+		assert.Equal(t, "joined", read(t, first).Text)
+		assert.NoError(t, second.WriteJSON(wsTestMessage{Room: "general", Text: "hi"}))
+		assert.Equal(t, "hi", read(t, first).Text)
+		assert.Equal(t, "hi", read(t, second).Text)
+
+		assert.Equal(t, 2, http.WSMetrics().Rooms["general"])
+		assert.GreaterOrEqual(t, http.WSMetrics().Connections, 2)
+	})
+
+	t.Run("Should close open connections on shutdown", func(t *testing.T) {
+		conn := dial(t)
+		assert.Eventually(t, func() bool { return http.WSMetrics().Connections > 0 }, time.Second, 5*time.Millisecond)
+
+		http.wsHub.close()
+
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, fastws.IsCloseError(err, fastws.CloseGoingAway), "unexpected error: %v", err)
+		assert.Eventually(t, func() bool { return http.WSMetrics().Connections == 0 }, time.Second, 5*time.Millisecond)
+	})
+}